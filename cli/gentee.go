@@ -26,12 +26,14 @@ func main() {
 	var (
 		env           string
 		testMode, ver bool
+		info          bool
 		err           error
 	)
 
 	flag.StringVar(&env, "env", "", "environment variables")
 	flag.BoolVar(&testMode, "t", false, "compare with #result")
 	flag.BoolVar(&ver, "ver", false, "compare with #result")
+	flag.BoolVar(&info, "info", false, "print description and usage of the script")
 	flag.Parse()
 
 	workspace := gentee.New()
@@ -48,6 +50,9 @@ func main() {
 
 	isError := func(code int) {
 		if err != nil {
+			if exit, ok := err.(*vm.ExitError); ok {
+				os.Exit(exit.Code)
+			}
 			fmt.Print(`ERROR`)
 			if errTrace, ok := err.(*vm.RuntimeError); ok {
 				fmt.Printf(" #%d: %s\n", errTrace.ID, err.Error())
@@ -75,6 +80,16 @@ func main() {
 	)
 	exec, unitID, err = workspace.CompileFile(script)
 	isError(errCompile)
+	if info {
+		unit := workspace.Unit(unitID)
+		if desc := unit.GetHeader(`description`); len(desc) > 0 {
+			fmt.Println(desc)
+		}
+		if usage := unit.GetHeader(`usage`); len(usage) > 0 {
+			fmt.Println(`Usage:`, usage)
+		}
+		return
+	}
 	settings.CmdLine = files[1:]
 	result, err = exec.Run(settings)
 	isError(errRun)
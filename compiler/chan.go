@@ -0,0 +1,103 @@
+// Copyright 2019 Alexey Krivonogov. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package compiler
+
+import (
+	"reflect"
+
+	"github.com/gentee/gentee/core"
+)
+
+func isChan(typeObj *core.TypeObject) bool {
+	return typeObj != nil && typeObj.Original == reflect.TypeOf(core.Chan{})
+}
+
+// chanCmd returns the command for Send(chan, value), Receive(chan) and ReceiveOk(chan) or nil if
+// the parameters are not suitable
+func chanCmd(cmpl *compiler, name string, params []*core.TypeObject) *core.CmdBlock {
+	if len(params) == 0 || !isChan(params[0]) || params[0].IndexOf == nil {
+		return nil
+	}
+	switch {
+	case name == `Send` && len(params) == 2:
+		return &core.CmdBlock{ID: core.StackSend}
+	case name == `Receive` && len(params) == 1:
+		return &core.CmdBlock{ID: core.StackReceive, Result: params[0].IndexOf}
+	case name == `ReceiveOk` && len(params) == 1:
+		// ReceiveOk returns the value and false if the channel has been closed
+		result := &core.TypeObject{Tuple: []*core.TypeObject{params[0].IndexOf,
+			cmpl.ws.StdLib().FindType(`bool`).(*core.TypeObject)}}
+		result.Name = `(` + params[0].IndexOf.GetName() + `, bool)`
+		result.Unit = cmpl.unit
+		return &core.CmdBlock{ID: core.StackReceiveOk, Result: result}
+	}
+	return nil
+}
+
+func coSelect(cmpl *compiler) error {
+	cmd := core.CmdBlock{ID: core.StackSelect, CmdCommon: core.CmdCommon{TokenID: uint32(cmpl.pos)}}
+	appendCmd(cmpl, &cmd)
+	cmpl.owners = append(cmpl.owners, &cmd)
+	return nil
+}
+
+func coSelectBack(cmpl *compiler) error {
+	if cmpl.curOwner().ID == core.StackSelect {
+		cmpl.owners = cmpl.owners[:len(cmpl.owners)-1]
+	}
+	return nil
+}
+
+func coSelectCase(cmpl *compiler) error {
+	coExpStart(cmpl)
+	cmd := core.CmdBlock{ID: core.StackSelectCase, CmdCommon: core.CmdCommon{TokenID: uint32(cmpl.pos)}}
+	appendCmd(cmpl, &cmd)
+	cmpl.owners = append(cmpl.owners, &cmd)
+	lp := cmpl.unit.Lexeme
+	if cmpl.pos+2 < len(lp.Tokens) && lp.Tokens[cmpl.pos+1].Type == tkIdent &&
+		lp.Tokens[cmpl.pos+2].Type == tkIn {
+		// case v in chan - the received value is passed as the parameter of the case block
+		cmpl.curType = nil
+		cmpl.pos++
+		if err := coVar(cmpl); err != nil {
+			return err
+		}
+		cmd.ParCount = 1
+		cmpl.newPos = cmpl.pos + 1
+	}
+	return nil
+}
+
+func coSelectCaseBack(cmpl *compiler) error {
+	cmd := cmpl.curOwner()
+	if cmd.ID != core.StackSelectCase {
+		cmpl.owners = cmpl.owners[:len(cmpl.owners)-2]
+		return nil
+	}
+	if len(cmd.Children) == 0 {
+		return nil
+	}
+	exp := cmd.Children[0]
+	valid := len(cmd.Children) == 1
+	switch icmd, _ := exp.(*core.CmdBlock); {
+	case cmd.ParCount > 0:
+		valid = valid && isChan(exp.GetResult()) && exp.GetResult().IndexOf != nil
+		if valid {
+			cmd.Vars[0] = exp.GetResult().IndexOf
+		}
+	case icmd != nil && (icmd.ID == core.StackSend || icmd.ID == core.StackReceive):
+	default:
+		valid = valid && isIntResult(exp)
+	}
+	if !valid {
+		return cmpl.ErrorPos(exp.GetToken(), ErrSelectCase)
+	}
+	cmdCase := core.CmdBlock{ID: core.StackBlock, Parent: cmd,
+		CmdCommon: core.CmdCommon{TokenID: uint32(cmpl.pos)}}
+	cmd.Children = append(cmd.Children, &cmdCase)
+	cmpl.owners = append(cmpl.owners, &cmdCase)
+	cmpl.dynamic = &cmState{tkLCurly, cmLCurly, nil, nil, 0}
+	return nil
+}
@@ -20,36 +20,51 @@ func cmd2Code(linker *Linker, cmd core.ICmd, out *core.Bytecode) {
 	push := func(pars ...core.Bcode) {
 		out.Code = append(out.Code, pars...)
 	}
-	getIndex := func(cmdVar *core.CmdVar, command core.Bcode) {
+	jump := func(command, offset core.Bcode) {
+		linker.Jumps = append(linker.Jumps, len(out.Code))
+		push(command, offset)
+	}
+	varShift := func(cmdVar *core.CmdVar) (int, int) {
 		var (
 			shift  int
 			locOut bool
 		)
-		block := cmdVar.Block
 		for shift = len(linker.Blocks) - 1; shift >= 0; shift-- {
-			if linker.Blocks[shift].Block == block {
+			if linker.Blocks[shift].Block == cmdVar.Block {
 				break
 			}
 			if linker.Blocks[shift].IsLocal {
 				locOut = true
 			}
 		}
-		for i := len(cmdVar.Indexes) - 1; i >= 0; i-- {
-			cmd2Code(linker, cmdVar.Indexes[i].Cmd, out)
-		}
-		inType := int(type2Code(block.Vars[cmdVar.Index], out))
 		blockShift := len(linker.Blocks) - 1 - shift
 		if locOut {
 			blockShift = 0x0f00 + shift
 		}
+		return blockShift, shift
+	}
+	getIndex := func(cmdVar *core.CmdVar, command core.Bcode) {
+		block := cmdVar.Block
+		indexes := cmdVar.Indexes
+		if _, ok := block.Boxed[cmdVar.Index]; ok {
+			// the value of the boxed variable is the first item of the box
+			indexes = append([]core.CmdRet{{Cmd: &core.CmdValue{Value: int64(0),
+				Result: &core.TypeObject{Original: reflect.TypeOf(int64(0))}}, Type: block.Vars[cmdVar.Index]}},
+				indexes...)
+		}
+		for i := len(indexes) - 1; i >= 0; i-- {
+			cmd2Code(linker, indexes[i].Cmd, out)
+		}
+		inType := int(varCode(block, cmdVar.Index, out))
+		blockShift, shift := varShift(cmdVar)
 		push(core.Bcode(blockShift<<16)|command,
 			core.Bcode(inType<<16|linker.Blocks[shift].Vars[cmdVar.Index]))
 		if inType >= core.TYPESTRUCT {
 			structOffset(out, -len(out.Code)+1)
 		}
-		if len(cmdVar.Indexes) > 0 {
-			push(core.Bcode(len(cmdVar.Indexes)<<16) | core.INDEX)
-			for _, ival := range cmdVar.Indexes {
+		if len(indexes) > 0 {
+			push(core.Bcode(len(indexes)<<16) | core.INDEX)
+			for _, ival := range indexes {
 				retType := type2Code(ival.Type, out)
 				code := core.Bcode(inType<<16) | retType
 				if type2Code(ival.Cmd.GetResult(), out) == core.TYPESTR {
@@ -106,6 +121,15 @@ func cmd2Code(linker *Linker, cmd core.ICmd, out *core.Bytecode) {
 						structOffset(out, len(out.Code)-1)
 					}
 				}
+			} else if anyFunc.IsClosure {
+				push(core.Bcode(count<<16)|core.CLOSURE, core.Bcode(id))
+				for _, num := range anyFunc.Optional {
+					ptype := varCode(&obj.(*core.FuncObject).Block, num, out)
+					push(ptype<<16 | core.Bcode(num))
+					if ptype >= core.TYPESTRUCT {
+						structOffset(out, -len(out.Code)+1)
+					}
+				}
 			} else {
 				push(core.Bcode(count<<16)|core.CALLBYID, core.Bcode(id))
 			}
@@ -149,13 +173,40 @@ func cmd2Code(linker *Linker, cmd core.ICmd, out *core.Bytecode) {
 		}
 	case core.CtFunc:
 		anyFunc := cmd.(*core.CmdAnyFunc)
-		for _, param := range anyFunc.Children {
+		for j, param := range anyFunc.Children {
+			if cmdVar, ok := param.(*core.CmdVar); ok && anyFunc.IsClosure {
+				if _, boxed := cmdVar.Block.Boxed[cmdVar.Index]; boxed {
+					// the function literal gets the box of the variable
+					block := &anyFunc.Object.(*core.FuncObject).Block
+					if block.Boxed == nil {
+						block.Boxed = make(map[int]bool)
+					}
+					block.Boxed[anyFunc.Optional[j]] = false
+					blockShift, shift := varShift(cmdVar)
+					push(core.Bcode(blockShift<<16)|core.GETVAR,
+						core.Bcode(core.TYPEARR<<16|linker.Blocks[shift].Vars[cmdVar.Index]))
+					continue
+				}
+			}
 			cmd2Code(linker, param, out)
 		}
 		obj := cmd.GetObject()
 		count := len(anyFunc.Children)
 		if obj == nil {
-			cmd2Code(linker, anyFunc.FnVar, out)
+			if method, ok := anyFunc.FnVar.(*core.CmdBlock); ok && method.ID == core.StackMethod {
+				// the function is got by the type of the struct in the interface value
+				var shift int
+				for _, param := range anyFunc.Children[1:] {
+					if type2Code(param.GetResult(), out)&0xf == core.STACKANY {
+						shift++
+					}
+				}
+				push(core.Bcode(shift<<16)|core.METHOD,
+					core.Bcode(method.Result.ObjID<<8|int32(method.ParCount)))
+				getPos(linker, cmd, out)
+			} else {
+				cmd2Code(linker, anyFunc.FnVar, out)
+			}
 			push(core.Bcode(count<<16)|core.CALLBYID, 0)
 			getPos(linker, cmd, out)
 		} else if obj.GetType() == core.ObjEmbedded {
@@ -172,7 +223,7 @@ func cmd2Code(linker *Linker, cmd core.ICmd, out *core.Bytecode) {
 			}
 		} else if obj.GetType() == core.ObjFunc {
 			var optCount int
-			if optCount = len(anyFunc.Optional); optCount > 0 {
+			if optCount = len(anyFunc.Optional); optCount > 0 && !anyFunc.IsClosure {
 				push(core.Bcode(optCount<<16) | core.OPTPARS)
 				for _, num := range anyFunc.Optional {
 					ptype := type2Code(obj.(*core.FuncObject).Block.Vars[num], out)
@@ -186,12 +237,13 @@ func cmd2Code(linker *Linker, cmd core.ICmd, out *core.Bytecode) {
 			if obj.(*core.FuncObject).Block.Variadic {
 				block := obj.(*core.FuncObject).Block
 				vcount := count - block.ParCount
-				push(core.Bcode(vcount<<16 | core.ARRAY))
+				push(core.Bcode(vcount<<16|core.ARRAY), type2Code(block.Vars[block.ParCount], out))
 				for j := vcount - 1; j >= 0; j-- {
 					typeRet := anyFunc.Children[block.ParCount+j].GetResult()
 					itype := type2Code(typeRet, out)
 					var isarray int32
-					if itype == core.TYPEARR && isEqualTypes(block.Vars[block.ParCount], typeRet) {
+					if (itype == core.TYPEARR || itype == core.TYPEARRINT || itype == core.TYPEARRFLOAT) &&
+						isEqualTypes(block.Vars[block.ParCount], typeRet) {
 						isarray = 1
 					}
 					push(core.Bcode(isarray<<16) | itype)
@@ -205,10 +257,18 @@ func cmd2Code(linker *Linker, cmd core.ICmd, out *core.Bytecode) {
 			}
 		}
 	case core.CtBinary:
+		if value, ok := foldConst(linker, cmd); ok {
+			cmd2Code(linker, value, out)
+			break
+		}
 		cmd2Code(linker, cmd.(*core.CmdBinary).Left, out)
 		cmd2Code(linker, cmd.(*core.CmdBinary).Right, out)
 		callFunc(2)
 	case core.CtUnary:
+		if value, ok := foldConst(linker, cmd); ok {
+			cmd2Code(linker, value, out)
+			break
+		}
 		cmd2Code(linker, cmd.(*core.CmdUnary).Operand, out)
 		callFunc(1)
 	case core.CtValue:
@@ -261,7 +321,9 @@ func cmd2Code(linker *Linker, cmd core.ICmd, out *core.Bytecode) {
 	case core.CtConst:
 		callFunc(1)
 	case core.CtVar:
-		getIndex(cmd.(*core.CmdVar), core.GETVAR)
+		if !loadStored(linker, cmd.(*core.CmdVar), out) {
+			getIndex(cmd.(*core.CmdVar), core.GETVAR)
+		}
 	case core.CtStack:
 		cmdStack := cmd.(*core.CmdBlock)
 		switch cmdStack.ID {
@@ -284,7 +346,7 @@ func cmd2Code(linker *Linker, cmd core.ICmd, out *core.Bytecode) {
 						push(core.Bcode(cmpType<<16)|core.JEQ, 0)
 					}
 					pos := len(out.Code)
-					push(core.JMP, 0)
+					jump(core.JMP, 0)
 					for _, icase := range cases {
 						out.Code[icase+1] = core.Bcode(len(out.Code) - icase)
 					}
@@ -292,7 +354,7 @@ func cmd2Code(linker *Linker, cmd core.ICmd, out *core.Bytecode) {
 					out.BlockFlags = core.BlBreak
 					cmd2Code(linker, caseStack.Children[len(caseStack.Children)-1], out)
 					offsets = append(offsets, len(out.Code))
-					push(core.JMP, 0)
+					jump(core.JMP, 0)
 					out.Code[pos+1] = core.Bcode(len(out.Code) - pos)
 					cmds = cmds[:0]
 				}
@@ -358,13 +420,21 @@ func cmd2Code(linker *Linker, cmd core.ICmd, out *core.Bytecode) {
 				structOffset(out, -len(out.Code)+1)
 			}
 		case core.StackQuestion:
+			if cond, ok := constCondition(linker, cmdStack.Children[0]); ok {
+				if cond {
+					cmd2Code(linker, cmdStack.Children[1], out)
+				} else {
+					cmd2Code(linker, cmdStack.Children[2], out)
+				}
+				break
+			}
 			cmd2Code(linker, cmdStack.Children[0], out)
 			pos := len(out.Code)
-			push(core.JZE, 0)
+			jump(core.JZE, 0)
 			cmd2Code(linker, cmdStack.Children[1], out)
 			out.Code[pos+1] = core.Bcode(len(out.Code) - pos + 2)
 			pos = len(out.Code)
-			push(core.JMP, 0)
+			jump(core.JMP, 0)
 			cmd2Code(linker, cmdStack.Children[2], out)
 			out.Code[pos+1] = core.Bcode(len(out.Code) - pos)
 		case core.StackAnd, core.StackOr:
@@ -374,10 +444,21 @@ func cmd2Code(linker *Linker, cmd core.ICmd, out *core.Bytecode) {
 				logic = core.JNZ
 			}
 			pos := len(out.Code)
-			push(core.DUP, logic, 0) //core.Bcode(size))
+			push(core.DUP)
+			jump(logic, 0)
 			cmd2Code(linker, cmdStack.Children[1], out)
 			out.Code[pos+2] = core.Bcode(len(out.Code) - pos - 3 + 2)
 		case core.StackAssign, core.StackIncDec:
+			if cmdVar, value, post, ok := incVar(linker, cmdStack, out); ok {
+				blockShift, shift := varShift(cmdVar)
+				var flag int
+				if post {
+					flag = 1
+				}
+				push(core.Bcode(blockShift<<16)|core.INCVAR,
+					core.Bcode(flag<<16|linker.Blocks[shift].Vars[cmdVar.Index]), core.Bcode(value))
+				break
+			}
 			rightType := core.Bcode(core.TYPEINT)
 			if cmdStack.ID == core.StackAssign {
 				cmd2Code(linker, cmdStack.Children[1], out)
@@ -416,33 +497,46 @@ func cmd2Code(linker *Linker, cmd core.ICmd, out *core.Bytecode) {
 		case core.StackIf:
 			var k int
 			lenIf := len(cmdStack.Children) >> 1
-			jumps := make([]int, lenIf)
+			jumps := make([]int, 0, lenIf)
+			isElse := len(cmdStack.Children)&1 == 1
 			for k = 0; k < lenIf; k++ {
+				if cond, ok := constCondition(linker, cmdStack.Children[k<<1]); ok {
+					// the branch is skipped or it is the last branch if the condition is constant
+					if cond {
+						cmd2Code(linker, cmdStack.Children[(k<<1)+1], out)
+						isElse = false
+						break
+					}
+					continue
+				}
 				cmd2Code(linker, cmdStack.Children[k<<1], out)
 				pos := len(out.Code)
-				push(core.JZE, 0)
+				jump(core.JZE, 0)
 				cmd2Code(linker, cmdStack.Children[(k<<1)+1], out)
-				jumps[k] = len(out.Code)
-				push(core.JMP, core.Bcode(jumps[k]))
+				jumps = append(jumps, len(out.Code))
+				jump(core.JMP, 0)
 				out.Code[pos+1] = core.Bcode(len(out.Code) - pos)
 			}
-			if len(cmdStack.Children)&1 == 1 {
+			if isElse {
 				cmd2Code(linker, cmdStack.Children[len(cmdStack.Children)-1], out)
 			}
 			for _, off := range jumps {
 				out.Code[off+1] = core.Bcode(len(out.Code) - off)
 			}
 		case core.StackWhile:
+			if cond, ok := constCondition(linker, cmdStack.Children[0]); ok && !cond {
+				break
+			}
 			pos := len(out.Code)
 			push(core.CYCLE)
 			getPos(linker, cmdStack, out)
 			cmd2Code(linker, cmdStack.Children[0], out)
-			push(core.JZE, 0) // core.Bcode(save(cmdStack.Children[1])+2))
+			jump(core.JZE, 0) // core.Bcode(save(cmdStack.Children[1])+2))
 			blockStart := len(out.Code)
 			out.BlockFlags = core.BlContinue | core.BlBreak
 			cmd2Code(linker, cmdStack.Children[1], out)
 			out.Code[blockStart-1] = core.Bcode(len(out.Code) - blockStart + 4)
-			push(core.JMP, core.Bcode(pos-len(out.Code)))
+			jump(core.JMP, core.Bcode(pos-len(out.Code)))
 			out.Code[blockStart+1] = core.Bcode(len(out.Code) - blockStart) // set break of BLOCK
 			out.Code[blockStart+2] = core.Bcode(pos - blockStart)           // set continue of BLOCK
 		case core.StackFor:
@@ -451,6 +545,10 @@ func cmd2Code(linker *Linker, cmd core.ICmd, out *core.Bytecode) {
 			cmd2Code(linker, cmdStack.Children[0], out)
 			srcType := type2Code(cmdStack.Children[0].GetResult(), out)
 			curType := type2Code(cmdStack.Vars[0], out)
+			if srcType == core.TYPECHAN {
+				forChan(linker, cmdStack, bInfo, curType, out)
+				break
+			}
 			// we don't need to use structOffset because for doesn't support structs
 			indcur := 0
 			if curType&0xf == core.STACKANY {
@@ -462,7 +560,7 @@ func cmd2Code(linker *Linker, cmd core.ICmd, out *core.Bytecode) {
 			push(core.GETVAR, core.Bcode(int(core.TYPEINT)<<16|bInfo.Vars[1]),
 				(srcType<<16)|core.DUP, (srcType<<16)|core.LEN, core.LT)
 			posJmp := len(out.Code)
-			push(core.JZE, 0)
+			jump(core.JZE, 0)
 			push(core.GETVAR, core.Bcode(int(core.TYPEINT)<<16|bInfo.Vars[1])) // set index
 			push(core.GETVAR, core.Bcode(int(srcType)<<16|indcur),             // get cur value
 				core.Bcode(1<<16|core.INDEX), core.Bcode(int(srcType)<<16)|curType)
@@ -473,30 +571,66 @@ func cmd2Code(linker *Linker, cmd core.ICmd, out *core.Bytecode) {
 			cmd2Code(linker, cmdStack.Children[1], out)
 			out.Code[blockStart+2] = core.Bcode(len(out.Code) - blockStart) // set continue of BLOCK
 			push(core.Bcode(bInfo.Vars[1]<<16) | core.FORINC)
-			push(core.JMP, core.Bcode(pos-len(out.Code)))
+			jump(core.JMP, core.Bcode(pos-len(out.Code)))
 			out.Code[blockStart+1] = core.Bcode(len(out.Code) - blockStart) // set break of BLOCK
 			out.Code[posJmp+1] = core.Bcode(len(out.Code) - posJmp)
 			push(core.DELVARS)
 			linker.Blocks = linker.Blocks[:len(linker.Blocks)-1]
 
-		case core.StackBlock, core.StackDefault:
+		case core.StackBlock, core.StackDefault, core.StackFinally:
+			ind, isDefer := cmdStack.VarNames[deferVar]
+			if isDefer {
+				out.BlockFlags |= core.BlFinally
+			}
+			blockStart := len(out.Code)
 			initBlock(linker, cmdStack, out)
 			for _, item := range cmdStack.Children {
 				cmd2Code(linker, item, out)
+				storeVar(linker, item, out)
+			}
+			if isDefer {
+				// the deferred calls are run at any exit from the function
+				out.Code[blockStart+1] = core.Bcode(len(out.Code) - blockStart)
+				push(core.FINALLY)
+				cmd2Code(linker, &core.CmdVar{Block: cmdStack, Index: ind}, out)
+				push(core.DEFERS, core.ENDFINALLY)
 			}
 			push(core.DELVARS)
 			linker.Blocks = linker.Blocks[:len(linker.Blocks)-1]
 			//deleteVars(rt)
 		case core.StackReturn:
-			if cmdStack.Children != nil {
-				cmd2Code(linker, cmdStack.Children[0], out)
-				retType := type2Code(cmdStack.Children[0].GetResult(), out)
-				push((retType << 16) | core.RET)
-				if retType >= core.TYPESTRUCT {
-					structOffset(out, -len(out.Code)+1)
+			if cmdStack.Children == nil {
+				push(core.TYPENONE<<16 | core.RET)
+				break
+			}
+			for _, item := range cmdStack.Children {
+				cmd2Code(linker, item, out)
+			}
+			if len(cmdStack.Children) == 1 {
+				if counts, ok := tailCall(linker, cmdStack.Children[0], out); ok {
+					// CALLBYID is replaced with TAILCALL which reuses the frame of the current function
+					id := out.Code[len(out.Code)-1]
+					out.Code[len(out.Code)-2] = out.Code[len(out.Code)-2]&^0xffff | core.TAILCALL
+					out.Code[len(out.Code)-1] = counts
+					push(id)
+					out.Pos[len(out.Pos)-1].Offset++
 				}
-			} else {
-				push(core.END)
+			}
+			results := cmdStack.Children[0].GetResult().Tuple
+			if len(cmdStack.Children) > 1 {
+				results = make([]*core.TypeObject, len(cmdStack.Children))
+				for i, item := range cmdStack.Children {
+					results[i] = item.GetResult()
+				}
+			}
+			if results != nil {
+				push(core.RETS, stackCounts(results, out))
+				break
+			}
+			retType := type2Code(cmdStack.Children[0].GetResult(), out)
+			push((retType << 16) | core.RET)
+			if retType >= core.TYPESTRUCT {
+				structOffset(out, -len(out.Code)+1)
 			}
 		case core.StackOptional:
 			pos := len(out.Code)
@@ -510,7 +644,7 @@ func cmd2Code(linker *Linker, cmd core.ICmd, out *core.Bytecode) {
 				IsLocal: true,
 			})
 			pos := len(out.Code)
-			push(core.JMP, 0) //core.Bcode(save(cmdStack.Children[0]))+3)
+			jump(core.JMP, 0) //core.Bcode(save(cmdStack.Children[0]))+3)
 			out.Locals = append(out.Locals, core.Local{
 				Cmd:    cmdStack.Children[0].(*core.CmdBlock),
 				Offset: len(out.Code),
@@ -536,25 +670,236 @@ func cmd2Code(linker *Linker, cmd core.ICmd, out *core.Bytecode) {
 			push(core.Bcode((len(cmdStack.Children)-1)<<16)|core.LOCAL,
 				core.Bcode(offset-len(out.Code)-1))
 		case core.StackLocret:
+			if cmdStack.Children == nil {
+				push(core.TYPENONE<<16 | core.RET)
+				break
+			}
 			cmd2Code(linker, cmdStack.Children[0], out)
 			retType := type2Code(cmdStack.Children[0].GetResult(), out)
 			push((retType << 16) | core.RET)
 			if retType >= core.TYPESTRUCT {
 				structOffset(out, -len(out.Code)+1)
 			}
+		case core.StackDestruct:
+			cmd2Code(linker, cmdStack.Children[0], out)
+			// the values are assigned from the last one because they are on the top of stacks
+			for i := len(cmdStack.Children) - 1; i > 0; i-- {
+				cmdVar := cmdStack.Children[i].(*core.CmdVar)
+				varType := type2Code(cmdVar.GetResult(), out)
+				getIndex(cmdVar, core.SETVAR)
+				push(varType<<16 | core.ASSIGN)
+				if varType >= core.TYPESTRUCT {
+					structOffset(out, -len(out.Code)+1)
+				}
+				push(varType<<16 | core.POP)
+			}
+		case core.StackIface:
+			cmd2Code(linker, cmdStack.Children[0], out)
+			structType := cmdStack.Children[0].GetResult()
+			for i, funcObj := range cmdStack.Result.Iface.Impl[structType] {
+				if out.Methods == nil {
+					out.Methods = make(map[int64]int32)
+				}
+				out.Methods[core.MethodKey(structType.ObjID, cmdStack.Result.ObjID<<8|int32(i))] =
+					funcObj.ObjID
+				useFunc(funcObj, out)
+			}
 		case core.StackTry:
-			out.BlockFlags = core.BlTry
+			var blockFinally int
+			finally := isFinally(cmdStack)
+			if finally {
+				// try and catch blocks are inside the block which runs finally code at any exit
+				out.BlockFlags = core.BlFinally
+				blockFinally = len(out.Code)
+				initBlock(linker, &core.CmdBlock{ID: core.StackBlock}, out)
+			}
+			catch := !finally || len(cmdStack.Children) > 2
+			if catch {
+				out.BlockFlags = core.BlTry
+			}
 			blockTry := len(out.Code)
 			cmd2Code(linker, cmdStack.Children[0], out)
-			pos := len(out.Code)
-			push(core.JMP, 0)
-			out.Code[blockTry+1] = core.Bcode(len(out.Code) - blockTry)
-			blockCatch := len(out.Code)
-			out.BlockFlags = core.BlRecover | core.BlRetry
-			cmd2Code(linker, cmdStack.Children[1], out)
-			out.Code[pos+1] = core.Bcode(len(out.Code) - pos)
-			out.Code[blockCatch+1] = core.Bcode(len(out.Code) - blockCatch) // recover jump
-			out.Code[blockCatch+2] = core.Bcode(blockTry - blockCatch)      // retry jump
+			if catch {
+				pos := len(out.Code)
+				jump(core.JMP, 0)
+				out.Code[blockTry+1] = core.Bcode(len(out.Code) - blockTry)
+				blockCatch := len(out.Code)
+				out.BlockFlags = core.BlRecover | core.BlRetry
+				catches := cmdStack.Children[1:]
+				if finally {
+					catches = catches[:len(catches)-1]
+				}
+				if len(catches) == 1 && catches[0].(*core.CmdBlock).ID != core.StackCatch {
+					cmd2Code(linker, catches[0], out)
+				} else {
+					catchCode(linker, catches, out)
+				}
+				out.Code[pos+1] = core.Bcode(len(out.Code) - pos)
+				out.Code[blockCatch+1] = core.Bcode(len(out.Code) - blockCatch) // recover jump
+				out.Code[blockCatch+2] = core.Bcode(blockTry - blockCatch)      // retry jump
+			}
+			if finally {
+				out.Code[blockFinally+1] = core.Bcode(len(out.Code) - blockFinally)
+				push(core.FINALLY)
+				cmd2Code(linker, cmdStack.Children[len(cmdStack.Children)-1], out)
+				push(core.ENDFINALLY, core.DELVARS)
+				linker.Blocks = linker.Blocks[:len(linker.Blocks)-1]
+			}
+		case core.StackDefer:
+			for _, item := range cmdStack.Children {
+				cmd2Code(linker, item, out)
+			}
+			push(core.DEFER)
+		case core.StackSend:
+			for _, item := range cmdStack.Children {
+				cmd2Code(linker, item, out)
+			}
+			push(type2Code(cmdStack.Children[1].GetResult(), out)<<16 | core.SEND)
+			getPos(linker, cmdStack, out)
+		case core.StackReceive:
+			cmd2Code(linker, cmdStack.Children[0], out)
+			push(type2Code(cmdStack.Result, out)<<16|core.RECEIVE, 0)
+			getPos(linker, cmdStack, out)
+		case core.StackReceiveOk:
+			cmd2Code(linker, cmdStack.Children[0], out)
+			push(type2Code(cmdStack.Result.Tuple[0], out)<<16 | core.RECEIVEOK)
+			getPos(linker, cmdStack, out)
+		case core.StackSelect:
+			selectCode(linker, cmdStack, out)
 		}
 	}
 }
+
+// catchCode generates the block which passes the error to the first catch block
+// with the suitable filter. The error is thrown again if there is no such catch block.
+func catchCode(linker *Linker, catches []core.ICmd, out *core.Bytecode) {
+	push := func(pars ...core.Bcode) {
+		out.Code = append(out.Code, pars...)
+	}
+	first := catches[0].(*core.CmdBlock)
+	if first.ID == core.StackCatch {
+		first = first.Children[0].(*core.CmdBlock)
+	}
+	errBlock := &core.CmdBlock{ID: core.StackBlock, ParCount: 1, Vars: first.Vars[:1],
+		CmdCommon: core.CmdCommon{TokenID: first.TokenID}}
+	errVar := &core.CmdVar{Block: errBlock, CmdCommon: errBlock.CmdCommon}
+	initBlock(linker, errBlock, out)
+	var ends []int
+	filter := core.Bcode(core.TYPENONE)
+	for _, item := range catches {
+		block := item.(*core.CmdBlock)
+		cmd2Code(linker, errVar, out)
+		filter = core.TYPENONE
+		if block.ID == core.StackCatch {
+			cmd2Code(linker, block.Children[1], out)
+			filter = type2Code(block.Children[1].GetResult(), out)
+			block = block.Children[0].(*core.CmdBlock)
+		}
+		pos := len(out.Code)
+		push(filter<<16|core.CATCH, 0)
+		cmd2Code(linker, block, out)
+		ends = append(ends, len(out.Code))
+		push(core.JMP, 0)
+		out.Code[pos+1] = core.Bcode(len(out.Code) - pos)
+	}
+	if filter != core.TYPENONE {
+		cmd2Code(linker, errVar, out)
+		push(core.THROW)
+	}
+	for _, pos := range ends {
+		out.Code[pos+1] = core.Bcode(len(out.Code) - pos)
+	}
+	push(core.DELVARS)
+	linker.Blocks = linker.Blocks[:len(linker.Blocks)-1]
+}
+
+// forChan generates the loop which receives the values from the channel until it is closed
+func forChan(linker *Linker, cmdStack *core.CmdBlock, bInfo BlockInfo, curType core.Bcode,
+	out *core.Bytecode) {
+	push := func(pars ...core.Bcode) {
+		out.Code = append(out.Code, pars...)
+	}
+	indcur := 0
+	if curType&0xf == core.STACKANY {
+		indcur = 1
+	}
+	pos := len(out.Code)
+	push(core.CYCLE)
+	getPos(linker, cmdStack, out)
+	push(core.GETVAR, core.Bcode(int(core.TYPECHAN)<<16|indcur))
+	posJmp := len(out.Code)
+	push(curType<<16|core.RECEIVE, 0)
+	getPos(linker, cmdStack, out)
+	push(core.SETVAR, core.Bcode(int(curType)<<16|bInfo.Vars[0]),
+		core.Bcode(int(curType)<<16|core.ASSIGNPTR), core.Bcode(int(curType)<<16|core.POP))
+	blockStart := len(out.Code)
+	out.BlockFlags = core.BlContinue | core.BlBreak
+	cmd2Code(linker, cmdStack.Children[1], out)
+	out.Code[blockStart+2] = core.Bcode(len(out.Code) - blockStart) // set continue of BLOCK
+	linker.Jumps = append(linker.Jumps, len(out.Code))
+	push(core.JMP, core.Bcode(pos-len(out.Code)))
+	out.Code[blockStart+1] = core.Bcode(len(out.Code) - blockStart) // set break of BLOCK
+	out.Code[posJmp+1] = core.Bcode(len(out.Code) - posJmp)
+	push(core.DELVARS)
+	linker.Blocks = linker.Blocks[:len(linker.Blocks)-1]
+}
+
+// selectCode generates SELECT with the table of cases and the code of case blocks
+func selectCode(linker *Linker, cmdStack *core.CmdBlock, out *core.Bytecode) {
+	push := func(pars ...core.Bcode) {
+		out.Code = append(out.Code, pars...)
+	}
+	cases := make([]core.Bcode, 0, 2*len(cmdStack.Children))
+	for _, item := range cmdStack.Children {
+		caseStack := item.(*core.CmdBlock)
+		if caseStack.ID == core.StackDefault {
+			cases = append(cases, core.SelDefault, 0)
+			continue
+		}
+		var kind core.Bcode
+		switch icmd, _ := caseStack.Children[0].(*core.CmdBlock); {
+		case caseStack.ParCount > 0:
+			cmd2Code(linker, caseStack.Children[0], out)
+			kind = type2Code(caseStack.Vars[0], out)<<16 | core.SelReceive
+		case icmd != nil && icmd.ID == core.StackSend:
+			for _, child := range icmd.Children {
+				cmd2Code(linker, child, out)
+			}
+			kind = type2Code(icmd.Children[1].GetResult(), out)<<16 | core.SelSend
+		case icmd != nil && icmd.ID == core.StackReceive:
+			// the received value is not used
+			cmd2Code(linker, icmd.Children[0], out)
+			kind = core.SelReceive
+		default:
+			cmd2Code(linker, caseStack.Children[0], out)
+			kind = core.SelTimeout
+		}
+		cases = append(cases, kind, 0)
+	}
+	pos := len(out.Code)
+	push(core.Bcode(len(cmdStack.Children)<<16)|core.SELECT, 0)
+	push(cases...)
+	getPos(linker, cmdStack, out)
+	offsets := make([]int, 0, 2*len(cmdStack.Children))
+	for i, item := range cmdStack.Children {
+		caseStack := item.(*core.CmdBlock)
+		out.Code[pos+3+2*i] = core.Bcode(len(out.Code) - pos)
+		offsets = append(offsets, len(out.Code))
+		out.BlockFlags = core.BlBreak
+		if caseStack.ID == core.StackDefault {
+			cmd2Code(linker, caseStack, out)
+		} else {
+			initBlock(linker, caseStack, out)
+			cmd2Code(linker, caseStack.Children[1], out)
+			push(core.DELVARS)
+			linker.Blocks = linker.Blocks[:len(linker.Blocks)-1]
+		}
+		offsets = append(offsets, len(out.Code))
+		linker.Jumps = append(linker.Jumps, len(out.Code))
+		push(core.JMP, 0)
+	}
+	out.Code[pos+1] = core.Bcode(len(out.Code) - pos)
+	for _, ioff := range offsets {
+		out.Code[ioff+1] = core.Bcode(len(out.Code) - ioff)
+	}
+}
@@ -36,6 +36,8 @@ type compiler struct {
 	next        *cmState
 	dynamic     *cmState
 	goStack     []goStack
+	fnLits      []fnLit                     // function literals which are being compiled
+	typeParams  map[string]*core.TypeObject // types of the instance of the generic function
 }
 
 type optInfo struct {
@@ -144,17 +146,10 @@ func (cmpl *compiler) latestFunc() *core.FuncObject {
 	return cmpl.ws.Objects[cmpl.curFunc].(*core.FuncObject)
 }
 
-// Compile compiles the source code
-func Compile(ws *core.Workspace, input, path string) (int, error) {
-
-	countObjects := len(ws.Objects)
-	countUnits := len(ws.Units)
-
-	lp, errID := LexParsing([]rune(input))
-	lp.Path = path
-	cmpl := &compiler{
+func newCompiler(ws *core.Workspace, unit *core.Unit) *compiler {
+	return &compiler{
 		ws:      ws,
-		unit:    ws.InitUnit(),
+		unit:    unit,
 		lexems:  []int{0}, // added lp in Lexeme
 		runID:   core.Undefined,
 		owners:  make([]core.ICmd, 0, 128),
@@ -162,6 +157,17 @@ func Compile(ws *core.Workspace, input, path string) (int, error) {
 		expbuf:  make([]ExpBuf, 0, 128),
 		curIota: core.NotIota,
 	}
+}
+
+// Compile compiles the source code
+func Compile(ws *core.Workspace, input, path string) (int, error) {
+
+	countObjects := len(ws.Objects)
+	countUnits := len(ws.Units)
+
+	lp, errID := LexParsing([]rune(input))
+	lp.Path = path
+	cmpl := newCompiler(ws, ws.InitUnit())
 	cmpl.unit.Lexeme = lp
 	if err := cmpl.copyNameSpace(ws.StdLib(), true); err != nil {
 		return core.Undefined, err
@@ -189,14 +195,45 @@ func Compile(ws *core.Workspace, input, path string) (int, error) {
 		cmpl.pos = len(lp.Tokens) - 1
 		return cmplError(errID)
 	}
+	if err := cmpl.checkHeader(); err != nil {
+		return cmplError(err)
+	}
 
+	if err := cmpl.compileTokens(0, -1); err != nil {
+		return cmplError(err)
+	}
+
+	if cmpl.runID != core.Undefined {
+		cmpl.unit.RunID = cmpl.runID
+		if len(cmpl.unit.Name) == 0 {
+			cmpl.unit.Name = path
+		}
+		/*		if unitIndex, ok := ws.UnitNames[cmpl.unit.Name]; ok {
+				if ws.Units[unitIndex].Lexeme[0].Path != path {
+					fmt.Println(unitIndex, path, `LEX`, ws.Units[unitIndex].Lexeme[0].Path, `Name`, cmpl.unit.Name)
+					return cmplError(cmpl.Error(ErrLink, cmpl.unit.Name))
+				}
+			}*/
+	}
+	ws.Units = append(ws.Units, cmpl.unit)
+	unitID := len(ws.Units) - 1
+	ws.UnitNames[cmpl.unit.Name] = unitID
+	ws.Units[unitID].Index = uint32(unitID)
+
+	return unitID, nil
+}
+
+// compileTokens compiles the tokens from start to end. If end is negative then
+// the tokens are compiled to the end of the source
+func (cmpl *compiler) compileTokens(start, end int) error {
+	lp := cmpl.unit.Lexeme
 	stackState := make([]StateStack, 0, 32)
 	state := cmMain
 main:
-	for i := 0; i < len(lp.Tokens); i++ {
+	for i := start; i < len(lp.Tokens) && (end < 0 || i <= end); i++ {
 		if cmpl.inits == 0 && lp.Tokens[i].Type == tkColon {
 			if err := colonToLine(cmpl, i); err != nil {
-				return cmplError(err)
+				return err
 			}
 		}
 		cmpl.pos = i
@@ -226,7 +263,7 @@ main:
 		if state == cmExp && token.Type == tkIdent {
 			isOpt, err := coOptionalFunc(cmpl)
 			if err != nil {
-				return cmplError(err)
+				return err
 			}
 			if isOpt {
 				i = cmpl.newPos
@@ -235,7 +272,7 @@ main:
 		}
 		if cmpl.next.Func != nil {
 			if err := cmpl.next.Func(cmpl); err != nil {
-				return cmplError(err)
+				return err
 			}
 			if cmpl.newPos != 0 {
 				i = cmpl.newPos
@@ -257,7 +294,7 @@ main:
 		}
 		if cmpl.next.State == cmBack {
 			if len(stackState) == 0 {
-				return cmplError(cmpl.Error(ErrCompiler, `Compile`))
+				return cmpl.Error(ErrCompiler, `Compile`)
 			}
 			for len(stackState) > 0 {
 				prev := stackState[len(stackState)-1]
@@ -265,7 +302,7 @@ main:
 				if prev.Origin.Callback != nil {
 					//cmpl.pos = prev.Pos
 					if err := prev.Origin.Callback(cmpl); err != nil {
-						return cmplError(err)
+						return err
 					}
 					if cmpl.dynamic != nil {
 						stackState = append(stackState, StateStack{Origin: cmpl.dynamic, Pos: i, State: state})
@@ -287,27 +324,9 @@ main:
 		state = cmpl.next.State
 	}
 	if len(stackState) > 0 {
-		return cmplError(cmpl.ErrorPos(len(lp.Tokens), ErrEnd))
+		return cmpl.ErrorPos(len(lp.Tokens), ErrEnd)
 	}
-
-	if cmpl.runID != core.Undefined {
-		cmpl.unit.RunID = cmpl.runID
-		if len(cmpl.unit.Name) == 0 {
-			cmpl.unit.Name = path
-		}
-		/*		if unitIndex, ok := ws.UnitNames[cmpl.unit.Name]; ok {
-				if ws.Units[unitIndex].Lexeme[0].Path != path {
-					fmt.Println(unitIndex, path, `LEX`, ws.Units[unitIndex].Lexeme[0].Path, `Name`, cmpl.unit.Name)
-					return cmplError(cmpl.Error(ErrLink, cmpl.unit.Name))
-				}
-			}*/
-	}
-	ws.Units = append(ws.Units, cmpl.unit)
-	unitID := len(ws.Units) - 1
-	ws.UnitNames[cmpl.unit.Name] = unitID
-	ws.Units[unitID].Index = uint32(unitID)
-
-	return unitID, nil
+	return nil
 }
 
 func colonToLine(cmpl *compiler, i int) error {
@@ -342,10 +361,27 @@ func isEqualTypes(left *core.TypeObject, right *core.TypeObject) bool {
 	if left == nil || right == nil {
 		return left == right
 	}
+	if left.Tuple != nil {
+		if len(left.Tuple) != len(right.Tuple) {
+			return false
+		}
+		for i, item := range left.Tuple {
+			if !isEqualTypes(item, right.Tuple[i]) {
+				return false
+			}
+		}
+		return true
+	}
 	switch left.Original {
 	case reflect.TypeOf(core.Fn{}):
-		if right.Original != reflect.TypeOf(core.Fn{}) ||
-			len(left.Func.Params) != len(right.Func.Params) ||
+		if right.Original != reflect.TypeOf(core.Fn{}) {
+			return false
+		}
+		// fn parameter of the embedded functions matches any fn type
+		if left.Func == nil || right.Func == nil {
+			return left.Func == nil
+		}
+		if len(left.Func.Params) != len(right.Func.Params) ||
 			!isEqualTypes(left.Func.Result, right.Func.Result) {
 			return false
 		}
@@ -373,33 +409,48 @@ func isEqualTypes(left *core.TypeObject, right *core.TypeObject) bool {
 			return true
 		}
 		return isEqualTypes(left.IndexOf, right.IndexOf)
+	case reflect.TypeOf(core.Chan{}):
+		if right.Original != reflect.TypeOf(core.Chan{}) {
+			return false
+		}
+		// compare for chan*
+		if left.IndexOf == nil || right.IndexOf == nil {
+			return true
+		}
+		return isEqualTypes(left.IndexOf, right.IndexOf)
 	}
 	return left == right
 }
 
 func autoType(cmpl *compiler, name string) (obj core.IObject, err error) {
-	if strings.HasSuffix(name, `.arr`) || strings.HasSuffix(name, `.map`) {
+	if strings.HasSuffix(name, `.arr`) || strings.HasSuffix(name, `.map`) ||
+		strings.HasSuffix(name, `.chan`) {
 		name += `.str`
 	}
+	if typeParam, ok := cmpl.typeParams[name]; ok {
+		return typeParam, nil
+	}
 	obj = cmpl.unit.FindType(name)
 	if obj == nil {
 		ins := strings.SplitN(name, `.`, 2)
-		if len(ins) == 2 {
-			if ins[0] == `arr` {
-				var indexOf core.IObject
-				indexOf, err = autoType(cmpl, ins[1])
-				if indexOf != nil {
-					if obj = cmpl.unit.NewType(name, reflect.TypeOf(core.Array{}), indexOf); obj != nil {
-						return
-					}
+		if len(ins) == 2 && (ins[0] == `arr` || ins[0] == `map` || ins[0] == `chan`) {
+			var indexOf core.IObject
+			indexOf, err = autoType(cmpl, ins[1])
+			if indexOf != nil {
+				// type parameters are replaced with the names of the types
+				name = ins[0] + `.` + indexOf.GetName()
+				if obj = cmpl.unit.FindType(name); obj != nil {
+					return
 				}
-			} else if ins[0] == `map` {
-				var indexOf core.IObject
-				indexOf, err = autoType(cmpl, ins[1])
-				if indexOf != nil {
-					if obj = cmpl.unit.NewType(name, reflect.TypeOf(core.Map{}), indexOf); obj != nil {
-						return
-					}
+				original := reflect.TypeOf(core.Array{})
+				switch ins[0] {
+				case `map`:
+					original = reflect.TypeOf(core.Map{})
+				case `chan`:
+					original = reflect.TypeOf(core.Chan{})
+				}
+				if obj = cmpl.unit.NewType(name, original, indexOf); obj != nil {
+					return
 				}
 			}
 		}
@@ -421,14 +472,25 @@ func getType(cmpl *compiler) (obj core.IObject, err error) {
 }
 
 func findVar(cmpl *compiler, token string) (*core.CmdBlock, int) {
-	block := cmpl.curOwner()
-	for block != nil {
+	return findBlockVar(cmpl, cmpl.curOwner(), len(cmpl.fnLits)-1, token)
+}
+
+// findBlockVar looks for the variable in the block and its parents. If the block belongs
+// to the function literal then the variable of the enclosing function is captured.
+func findBlockVar(cmpl *compiler, block *core.CmdBlock, level int, token string) (*core.CmdBlock, int) {
+	for {
 		if ind, ok := block.VarNames[token]; ok {
 			return block, ind
 		}
+		if block.Parent == nil {
+			break
+		}
 		block = block.Parent
 	}
-	return nil, 0
+	if level < 0 || block != &cmpl.fnLits[level].Func.Block {
+		return nil, 0
+	}
+	return captureVar(cmpl, level, token)
 }
 
 func coError(cmpl *compiler) error {
@@ -449,6 +511,24 @@ func coType(cmpl *compiler) error {
 	return nil
 }
 
+// coTypeOrVar defines the variable of the current type if the identifier is not a type.
+// The names separated by commas cannot follow the names separated by spaces.
+func coTypeOrVar(cmpl *compiler) error {
+	isType := func(token string) bool {
+		return strings.IndexRune(token, '.') >= 0 || cmpl.unit.FindType(token) != nil ||
+			cmpl.typeParams[token] != nil
+	}
+	if isType(getToken(cmpl.unit.Lexeme, cmpl.pos)) {
+		return nil
+	}
+	// the token before the previous name must be the type or the comma
+	if cmpl.unit.Lexeme.Tokens[cmpl.pos-3].Type == tkComma ||
+		isType(getToken(cmpl.unit.Lexeme, cmpl.pos-3)) {
+		cmpl.dynamic = &cmState{tkIdent, cmVar, nil, nil, cfStay}
+	}
+	return nil
+}
+
 func checkUsedName(cmpl *compiler, token string) error {
 	if cmpl.unit.FindType(token) != nil {
 		return cmpl.Error(ErrUsedName, token)
@@ -614,13 +694,17 @@ func coExpEnv(cmpl *compiler) error {
 }
 
 func isInLoop(cmpl *compiler, incase bool) bool {
-	for _, item := range cmpl.owners {
-		if item.GetType() == core.CtStack {
+	for i := len(cmpl.owners) - 1; i >= 0; i-- {
+		if item := cmpl.owners[i]; item.GetType() == core.CtStack {
 			id := item.(*core.CmdBlock).ID
 			if id == core.StackWhile || id == core.StackFor ||
 				(incase && (id == core.StackCase || id == core.StackDefault)) {
 				return true
 			}
+			if item.(*core.CmdBlock).Object != nil {
+				// the body of the function literal
+				break
+			}
 		}
 	}
 	return false
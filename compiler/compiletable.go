@@ -6,21 +6,27 @@ package compiler
 
 const (
 	// List of compile states
-	cmMain     = iota + 1
-	cmRun      // run command
-	cmLCurly   // {
-	cmBody     // body of the code
-	cmExp      // expression
-	cmExpIdent // identifier
-	cmExpOper  // expecting operator in expression
-	cmElseIf   // elif or else
-	cmFunc     // func command
-	cmParams   // parameters of the function
-	cmParam    // getting type name
+	cmMain       = iota + 1
+	cmRun        // run command
+	cmLCurly     // {
+	cmBody       // body of the code
+	cmExp        // expression
+	cmExpIdent   // identifier
+	cmExpOper    // expecting operator in expression
+	cmElseIf     // elif or else
+	cmFunc       // func command
+	cmMethod     // func or method name
+	cmMethodName // the name of the method
+	cmParams     // parameters of the function
+	cmGeneric    // the end of the generic function
+	cmResult     // the type of the result
+	cmResultNext // the next result or ')'
+	cmParam      // getting type name
 	cmWantVar
 	cmVar      // getting var name
 	cmWantRPar // expecting ')'
 	cmWantType
+	cmWantTypeVar    // type or the next variable of the same type
 	cmMustVarType    // define variables
 	cmOptional       // optional var
 	cmVarType        // define variables
@@ -34,9 +40,17 @@ const (
 	cmStructDef      // struct body
 	cmStructFields   // struct fields
 	cmStructName     // struct the name of the field
+	cmIface          // interface definition
+	cmIfaceDef       // interface body
+	cmIfaceItems     // fields and methods of the interface
+	cmIfaceItem      // the field or the method of the interface
+	cmIfaceParams    // parameters of the method
+	cmIfaceResult    // the result of the method
+	cmIfaceLine      // expecting the end of the line
 	cmFn             // func type definition
 	cmFnParams       // parameters of the func type
 	cmFnParam        // parameter of the func type
+	cmFnLit          // function literal
 	cmCaseMust
 	cmCase        // case after switch
 	cmInclude     // include command
@@ -46,6 +60,10 @@ const (
 	cmLocalParams
 	cmCatch // catch command
 	cmCatchIdent
+	cmCatchFilter
+	cmCatchNext // catch or finally after catch
+	cmSelectMust
+	cmSelect // case after select
 
 	cmBack // go to back
 
@@ -73,6 +91,7 @@ var (
 			{tkConst, cmConst, nil, coConstBack, cfStopBack},
 			{tkFunc, cmFunc, nil, coFuncBack, cfStopBack},
 			{tkStruct, cmStruct, nil, nil, cfStopBack},
+			{tkInterface, cmIface, nil, nil, cfStopBack},
 			{tkFn, cmFn, nil, nil, cfStopBack},
 			{tkInclude, cmInclude, coInclude, nil, cfStopBack},
 			{tkImport, cmInclude, coImport, nil, cfStopBack},
@@ -99,6 +118,7 @@ var (
 			{tkWhile, cmExp, coWhile, coWhileBack, cfStopBack},
 			{tkFor, cmExp, coFor, coForBack, cfStopBack},
 			{tkSwitch, cmExp, coSwitch, coSwitchBack, cfStopBack},
+			{tkSelect, cmSelectMust, coSelect, coSelectBack, cfStopBack},
 			{tkReturn, cmExp, coReturn, coReturnBack, cfStopBack},
 			{tkBreak, 0, coBreak, nil, 0},
 			{tkContinue, 0, coContinue, nil, 0},
@@ -106,6 +126,7 @@ var (
 			{tkRetry, 0, coRetry, nil, 0},
 			{tkLocal, cmLocal, nil, coLocalBack, cfStopBack},
 			{tkTry, cmLCurly, coTry, coTryBack, cfStopBack},
+			{tkDefer, cmExp, coDefer, coDeferBack, cfStopBack},
 		},
 		cmExp: {
 			{tkToken, ErrValue, coError, nil, 0},
@@ -123,6 +144,7 @@ var (
 			{tkEnv, cmExpOper, coExpEnv, nil, cfStopBack},
 			{tkQuestion, cmExpIdent, nil, nil, cfStopBack},
 			{tkGo, cmGo, coGo, coGoBack, cfStopBack},
+			{tkFn, cmFnLit, coFnLit, coFnLitBack, cfStopBack},
 		},
 		cmExpIdent: {
 			{tkToken, cmExpOper, coExpVar, nil, cfStay},
@@ -151,16 +173,39 @@ var (
 		},
 		cmFunc: {
 			{tkToken, ErrName, coError, nil, 0},
-			{tkIdent, cmParams, coFuncName, nil, 0},
+			{tkIdent, cmMethod, coFuncName, nil, 0},
 			{tkLine, 0, nil, nil, 0},
 		},
+		cmMethod: {
+			{tkToken, cmParams, nil, nil, cfStay},
+			{tkDot, cmMethodName, nil, nil, 0},
+		},
+		cmMethodName: {
+			{tkToken, ErrName, coError, nil, 0},
+			{tkIdent, cmParams, coMethodName, nil, 0},
+		},
+		cmGeneric: {
+			{tkToken, ErrEnd, coError, nil, 0},
+			{tkRCurly, cmBack, nil, nil, 0},
+		},
 		cmParams: {
 			{tkToken, ErrLCurly, coError, nil, 0},
 			{tkIdent, cmLCurly, coRetType, nil, 0},
-			{tkLPar, cmParam, nil, nil, cfStopBack},
+			{tkLPar, cmParam, coParamsResults, nil, cfStopBack},
 			{tkLCurly, cmLCurly, coFuncStart, nil, cfStay},
 			{tkLine, 0, nil, nil, 0},
 		},
+		cmResult: {
+			{tkToken, ErrType, coError, nil, 0},
+			{tkIdent, cmResultNext, coResultType, nil, 0},
+			{tkLine, 0, nil, nil, 0},
+		},
+		cmResultNext: {
+			{tkToken, ErrNotRPar, coError, nil, 0},
+			{tkComma, cmResult, nil, nil, 0},
+			{tkRPar, cmLCurly, coResults, nil, 0},
+			{tkLine, 0, nil, nil, 0},
+		},
 		cmParam: {
 			{tkToken, ErrType, coError, nil, 0},
 			{tkIdent, cmWantVar, coType, nil, cfStopBack},
@@ -175,7 +220,7 @@ var (
 		cmVar: {
 			{tkToken, ErrName, coError, nil, 0},
 			{tkIdent, 0, coVar, nil, 0},
-			{tkComma, cmWantType, nil, nil, 0},
+			{tkComma, cmWantTypeVar, nil, nil, 0},
 			{tkVariadic, cmWantRPar, coVariadic, nil, 0},
 			{tkRPar, cmBack, nil, nil, cfStay},
 			{tkLine, 0, nil, nil, 0},
@@ -190,6 +235,11 @@ var (
 			{tkIdent, cmBack, nil, nil, cfStay},
 			{tkLine, 0, nil, nil, 0},
 		},
+		cmWantTypeVar: {
+			{tkToken, ErrType, coError, nil, 0},
+			{tkIdent, cmBack, coTypeOrVar, nil, cfStay},
+			{tkLine, 0, nil, nil, 0},
+		},
 		cmMustVarType: {
 			{tkToken, ErrName, coError, nil, 0},
 			{tkIdent, cmVarType, coVarExp, nil, 0},
@@ -258,6 +308,44 @@ var (
 			{tkRCurly, cmBack, nil, nil, cfStay},
 			{tkLine, cmBack, nil, nil, 0},
 		},
+		cmIface: {
+			{tkToken, ErrName, coError, nil, 0},
+			{tkLine, 0, nil, nil, 0},
+			{tkIdent, cmIfaceDef, coIface, coStructEnd, 0},
+		},
+		cmIfaceDef: {
+			{tkToken, ErrLCurly, coError, nil, 0},
+			{tkLCurly, cmIfaceItems, nil, nil, 0},
+			{tkLine, 0, nil, nil, 0},
+		},
+		cmIfaceItems: {
+			{tkToken, ErrName, coError, nil, 0},
+			{tkLine, 0, nil, nil, 0},
+			{tkIdent, cmIfaceItem, nil, nil, cfStopBack},
+			{tkRCurly, cmBack, nil, nil, 0},
+		},
+		cmIfaceItem: {
+			{tkToken, ErrName, coError, nil, 0},
+			{tkIdent, cmIfaceLine, coIfaceField, nil, 0},
+			{tkLPar, cmIfaceParams, coIfaceMethod, nil, 0},
+		},
+		cmIfaceParams: {
+			{tkToken, ErrType, coError, nil, 0},
+			{tkIdent, 0, coIfaceParam, nil, 0},
+			{tkComma, 0, nil, nil, 0},
+			{tkRPar, cmIfaceResult, nil, nil, 0},
+		},
+		cmIfaceResult: {
+			{tkToken, ErrLineRCurly, coError, nil, 0},
+			{tkIdent, cmIfaceLine, coIfaceResult, nil, 0},
+			{tkLine, cmBack, nil, nil, cfStay},
+			{tkRCurly, cmBack, nil, nil, cfStay},
+		},
+		cmIfaceLine: {
+			{tkToken, ErrLineRCurly, coError, nil, 0},
+			{tkLine, cmBack, nil, nil, cfStay},
+			{tkRCurly, cmBack, nil, nil, cfStay},
+		},
 		cmFn: {
 			{tkToken, ErrName, coError, nil, 0},
 			{tkIdent, cmFnParams, coFn, coFnEnd, 0},
@@ -276,6 +364,13 @@ var (
 			{tkRPar, cmBack, nil, nil, 0},
 			{tkLine, 0, nil, nil, 0},
 		},
+		cmFnLit: {
+			{tkToken, ErrLCurly, coError, nil, 0},
+			{tkIdent, cmLCurly, coFnLitResult, nil, 0},
+			{tkLPar, cmParam, nil, nil, cfStopBack},
+			{tkLCurly, cmLCurly, coFnLitStart, nil, cfStay},
+			{tkLine, 0, nil, nil, 0},
+		},
 		cmCaseMust: {
 			{tkToken, ErrNotCase, coError, nil, 0},
 			{tkCase, cmCase, nil, nil, cfStay},
@@ -287,6 +382,17 @@ var (
 			{tkDefault, cmLCurly, coDefault, coDefaultBack, 0},
 			{tkLine, 0, nil, nil, 0},
 		},
+		cmSelectMust: {
+			{tkToken, ErrNotCase, coError, nil, 0},
+			{tkCase, cmSelect, nil, nil, cfStay},
+			{tkLine, 0, nil, nil, 0},
+		},
+		cmSelect: {
+			{tkToken, cmBack, nil, nil, cfStay},
+			{tkCase, cmExp, coSelectCase, coSelectCaseBack, cfStopBack},
+			{tkDefault, cmLCurly, coDefault, coDefaultBack, 0},
+			{tkLine, 0, nil, nil, 0},
+		},
 		cmInclude: {
 			{tkToken, ErrLCurly, coError, nil, 0},
 			{tkLCurly, cmIncludeFile, nil, nil, 0},
@@ -318,11 +424,23 @@ var (
 		cmCatch: {
 			{tkToken, ErrCatch, coError, nil, 0},
 			{tkLine, 0, nil, nil, 0},
-			{tkCatch, cmCatchIdent, nil, coCatchBack, 0},
+			{tkCatch, cmCatchIdent, nil, nil, 0},
+			{tkFinally, cmLCurly, coFinally, nil, 0},
 		},
 		cmCatchIdent: {
 			{tkToken, ErrName, coError, nil, 0},
-			{tkIdent, cmLCurly, coCatch, nil, 0},
+			{tkIdent, cmCatchFilter, coCatch, nil, 0},
+		},
+		cmCatchFilter: {
+			{tkToken, cmExp, coCatchFilter, nil, cfStay | cfStopBack},
+			{tkLine, 0, nil, nil, 0},
+			{tkLCurly, cmBody, coCatchBody, nil, 0},
+		},
+		cmCatchNext: {
+			{tkToken, cmBack, nil, nil, cfStay},
+			{tkLine, 0, nil, nil, 0},
+			{tkCatch, cmCatchIdent, nil, nil, 0},
+			{tkFinally, cmLCurly, coFinally, nil, 0},
 		},
 	}
 	compileTable [][tkToken]*cmState
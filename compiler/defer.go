@@ -0,0 +1,57 @@
+// Copyright 2019 Alexey Krivonogov. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package compiler
+
+import (
+	"reflect"
+
+	"github.com/gentee/gentee/core"
+)
+
+// deferVar is the name of the hidden variable which contains the deferred calls of the function.
+// It is a keyword so it cannot be used as the name of a variable.
+const deferVar = `defer`
+
+// coDefer compiles the calling of the function after defer as the body of a function literal.
+// The variables are captured when defer statement is executed.
+func coDefer(cmpl *compiler) error {
+	block := &cmpl.latestFunc().Block
+	if _, ok := block.VarNames[deferVar]; !ok {
+		if block.VarNames == nil {
+			block.VarNames = make(map[string]int)
+		}
+		block.VarNames[deferVar] = len(block.Vars)
+		block.Vars = append(block.Vars, &core.TypeObject{Original: reflect.TypeOf(core.Array{})})
+	}
+	lit := fnLit{
+		Outer:   cmpl.curOwner(),
+		CurType: cmpl.curType,
+		Pos:     cmpl.pos,
+		Type: &core.TypeObject{Original: reflect.TypeOf(core.Fn{}),
+			Func: &core.FnType{}},
+		IsDefer: true,
+	}
+	lit.Func = cmpl.ws.Objects[newFunc(cmpl, goExpPush(cmpl))].(*core.FuncObject)
+	cmpl.fnLits = append(cmpl.fnLits, lit)
+	return nil
+}
+
+func coDeferBack(cmpl *compiler) error {
+	lit := cmpl.fnLits[len(cmpl.fnLits)-1]
+	children := lit.Func.Block.Children
+	if len(children) != 1 || children[0].GetType() != core.CtFunc {
+		return cmpl.ErrorPos(lit.Pos, ErrDefer)
+	}
+	cmpl.fnLits = cmpl.fnLits[:len(cmpl.fnLits)-1]
+	goExpPop(cmpl)
+	cmpl.owners = cmpl.owners[:len(cmpl.owners)-1]
+	cmpl.curType = lit.CurType
+
+	block := &cmpl.latestFunc().Block
+	appendCmd(cmpl, &core.CmdBlock{ID: core.StackDefer, CmdCommon: core.CmdCommon{TokenID: uint32(lit.Pos)},
+		Children: []core.ICmd{&core.CmdVar{Block: block, Index: block.VarNames[deferVar],
+			CmdCommon: core.CmdCommon{TokenID: uint32(lit.Pos)}}, litValue(&lit)}})
+	return nil
+}
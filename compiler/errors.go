@@ -181,6 +181,30 @@ const (
 	ErrFnBuildIn
 	// ErrFnVariadic is returned when fn variable assigned to variadic function
 	ErrFnVariadic
+	// ErrFnCallback is returned when fn parameter doesn't suit the higher-order function
+	ErrFnCallback
+	// ErrMethod is returned when the method is declared for not struct type
+	ErrMethod
+	// ErrTypeParam is returned when the type parameter of the result is not used in parameters
+	ErrTypeParam
+	// ErrDestruct is returned when the count of values doesn't match the count of variables
+	ErrDestruct
+	// ErrDefer is returned when defer is not followed by the calling of a function
+	ErrDefer
+	// ErrCatchFilter is returned when the filter of catch is not int or range
+	ErrCatchFilter
+	// ErrCatchAll is returned when catch without a filter is followed by another catch
+	ErrCatchAll
+	// ErrSelectCase is returned when the case of select is not a channel operation or a timeout
+	ErrSelectCase
+	// ErrVersion is returned when the script requires the newer version of Gentee
+	ErrVersion
+	// ErrHeader is returned when the value of the standard header key is invalid
+	ErrHeader
+	// ErrCaptured is returned when the captured variable is changed in the function literal
+	ErrCaptured
+	// ErrTupleValue is returned when several results of the function are used as one value
+	ErrTupleValue
 
 	// ErrCompiler error. It means a bug.
 	ErrCompiler
@@ -278,12 +302,24 @@ var (
 		ErrLocalName:     `%s local function has already been defined`,
 		ErrLocalVariadic: `local function cannot have a variadic parameter`,
 		ErrGoParam:       `there is an unnamed parameter in go statement`,
-		ErrCatch:         `unexpected token, expecting 'catch'`,
+		ErrCatch:         `unexpected token, expecting 'catch' or 'finally'`,
 		ErrRecover:       `'recover' can only be inside catch`,
 		ErrRetry:         `'retry' can only be inside catch`,
 		ErrLinkIndex:     `incorrect link index %d`,
 		ErrFnBuildIn:     `fn variable can't be assigned to a built-in function`,
 		ErrFnVariadic:    `fn variable can't be assigned to a variadic function`,
+		ErrFnCallback:    `unsuitable fn parameter in %s%s`,
+		ErrMethod:        `%s is not a struct type`,
+		ErrTypeParam:     `type parameter %s is not used in parameters`,
+		ErrDestruct:      `the count of values (%d) doesn't match the count of variables (%d)`,
+		ErrDefer:         `defer requires the calling of a function`,
+		ErrCatchFilter:   `the filter of catch must be int or range`,
+		ErrCatchAll:      `catch without a filter must be the last one`,
+		ErrSelectCase:    `the case of select must be Send, Receive, 'var in chan' or int timeout`,
+		ErrVersion:       `the script requires Gentee version %s or higher`,
+		ErrHeader:        `invalid value of %s in the header`,
+		ErrCaptured:      `captured variable %s cannot be changed in the function literal`,
+		ErrTupleValue:    `several results of the function cannot be used as one value`,
 
 		ErrCompiler: `you have found a compiler bug [%s]. Let us know, please`,
 	}
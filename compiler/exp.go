@@ -119,6 +119,19 @@ func coExpVar(cmpl *compiler) error {
 			CmdCommon: core.CmdCommon{TokenID: uint32(cmpl.pos - 1)}}
 		typeVar := cmdVar.GetResult()
 		for _, field := range fields {
+			if typeVar.Iface != nil {
+				// the fields of the interface are got by names
+				typeField, ok := typeVar.Iface.Fields[field]
+				if !ok {
+					return cmpl.ErrorPos(cmpl.pos-1, ErrStruct, typeVar.GetName(), field)
+				}
+				index := &core.CmdValue{Value: field,
+					CmdCommon: core.CmdCommon{TokenID: uint32(cmpl.pos - 1)},
+					Result:    cmpl.getStrType()}
+				cmdVar.Indexes = append(cmdVar.Indexes, core.CmdRet{Cmd: index, Type: typeField})
+				typeVar = typeField
+				continue
+			}
 			indField, typeField, err := structIndex(cmpl, typeVar, field)
 			if err != nil {
 				return err
@@ -142,7 +155,9 @@ func coExpEnd(cmpl *compiler) error {
 	}
 	init := isInState(cmpl, cmInit, 0)
 	if len(cmpl.exp) > 1 && !init && !isCase(cmpl) {
-		return cmpl.Error(ErrCompiler, `coExpEnd`)
+		if cmpl.curOwner().ID != core.StackReturn {
+			return cmpl.Error(ErrOper)
+		}
 	}
 	for len(cmpl.exp) > 0 {
 		cmpl.curOwner().Children = append(cmpl.curOwner().Children, cmpl.exp[0])
@@ -162,6 +177,9 @@ func popBuf(cmpl *compiler) error {
 		}
 		right := cmpl.exp[len(cmpl.exp)-1]
 		left := cmpl.exp[len(cmpl.exp)-2]
+		if err := checkTuple(cmpl, left, right); err != nil {
+			return err
+		}
 		if !isBoolResult(left) || !isBoolResult(right) {
 			return cmpl.ErrorPos(expBuf.Pos, ErrBoolOper)
 		}
@@ -180,6 +198,9 @@ func popBuf(cmpl *compiler) error {
 		}
 		right := cmpl.exp[len(cmpl.exp)-1]
 		left := cmpl.exp[len(cmpl.exp)-2]
+		if err := checkTuple(cmpl, left, right); err != nil {
+			return err
+		}
 		obj = getOperator(cmpl, prior.Name, left, right)
 		if obj == nil {
 			return cmpl.ErrorFunction(ErrFunction, expBuf.Pos, prior.Name, []*core.TypeObject{
@@ -198,6 +219,11 @@ func popBuf(cmpl *compiler) error {
 		}
 		right := cmpl.exp[len(cmpl.exp)-1]
 		left := cmpl.exp[len(cmpl.exp)-2]
+		if expBuf.Oper != tkAssign {
+			if err := checkTuple(cmpl, right); err != nil {
+				return err
+			}
+		}
 
 		if expBuf.Oper == tkAssign && left.GetType() == core.CtUnary {
 			if left.GetObject() == cmpl.ws.StdLib().FindObj(core.DefGetEnv) {
@@ -215,14 +241,30 @@ func popBuf(cmpl *compiler) error {
 				break
 			}
 		}
+		if expBuf.Oper == tkAssign && len(cmpl.expbuf) == 1 && (isTuple(right) ||
+			len(cmpl.exp) > 2) && cmpl.curOwner().ID != core.StackNew && !isCase(cmpl) {
+			if err := destructAssign(cmpl, expBuf.Pos); err != nil {
+				return err
+			}
+			break
+		}
 		if left.GetType() != core.CtVar {
 			return cmpl.ErrorPos(expBuf.Pos, ErrLValue)
 		}
+		if err := checkCaptured(cmpl, left, expBuf.Pos); err != nil {
+			return err
+		}
 
 		obj = getOperator(cmpl, prior.Name, left, right)
 		if obj == nil {
 			if expBuf.Oper == tkAssign {
-				if left.GetResult().Custom != nil {
+				if left.GetResult().Iface != nil {
+					if right = toIface(cmpl, left.GetResult(), right); right == nil {
+						return cmpl.ErrorPos(expBuf.Pos, ErrStructAssign,
+							cmpl.exp[len(cmpl.exp)-1].GetResult().GetName(), left.GetResult().GetName())
+					}
+					obj = cmpl.ws.StdLib().FindObj(core.DefAssignIfaceIface)
+				} else if left.GetResult().Custom != nil {
 					if left.GetResult() != right.GetResult() {
 						return cmpl.ErrorPos(expBuf.Pos, ErrStructAssign, right.GetResult().GetName(),
 							left.GetResult().GetName())
@@ -239,6 +281,8 @@ func popBuf(cmpl *compiler) error {
 						obj = cmpl.unit.FindObj(core.DefAssignArr)
 					} else if left.GetResult().Original == reflect.TypeOf(core.Map{}) {
 						obj = cmpl.unit.FindObj(core.DefAssignMap)
+					} else if left.GetResult().Original == reflect.TypeOf(core.Chan{}) {
+						obj = cmpl.unit.FindObj(core.DefAssignChan)
 					}
 				}
 			} else if expBuf.Oper == tkBitAndEq {
@@ -285,6 +329,9 @@ func popBuf(cmpl *compiler) error {
 		}
 		right := cmpl.exp[len(cmpl.exp)-1]
 		left := cmpl.exp[len(cmpl.exp)-2]
+		if err := checkTuple(cmpl, left, right); err != nil {
+			return err
+		}
 		obj = getOperator(cmpl, prior.Name, left, right)
 		if obj == nil {
 			return cmpl.ErrorFunction(ErrFunction, expBuf.Pos, prior.Name, []*core.TypeObject{
@@ -316,6 +363,9 @@ func popBuf(cmpl *compiler) error {
 		if top.GetType() != core.CtVar {
 			return cmpl.ErrorPos(expBuf.Pos, ErrLValue)
 		}
+		if err := checkCaptured(cmpl, top, expBuf.Pos); err != nil {
+			return err
+		}
 		val := 1
 		if (expBuf.Oper & 0xff) == tkDec {
 			val = -1
@@ -333,6 +383,9 @@ func popBuf(cmpl *compiler) error {
 			return cmpl.Error(ErrValue)
 		}
 		top := cmpl.exp[len(cmpl.exp)-1]
+		if err := checkTuple(cmpl, top); err != nil {
+			return err
+		}
 		if expBuf.Oper == (tkMul | tkUnary) {
 			switch top.GetResult().Original {
 			case reflect.TypeOf(core.Array{}):
@@ -479,6 +532,9 @@ func appendExpBuf(cmpl *compiler, operation int) error {
 						for i := 0; i < numParams; i++ {
 							params = append(params, cmpl.exp[prevToken.LenExp+i].GetResult())
 						}
+						if err := checkTuple(cmpl, cmpl.exp[prevToken.LenExp:]...); err != nil {
+							return err
+						}
 						if nameFunc == `$` {
 							if len(cmpl.expbuf) == 1 && cmpl.curOwner().ID != core.StackReturn {
 								nameFunc = `Command`
@@ -501,6 +557,16 @@ func appendExpBuf(cmpl *compiler, operation int) error {
 							}
 							cmpl.exp = cmpl.exp[:len(cmpl.exp)-numParams]
 							cmpl.exp = append(cmpl.exp, icmd)
+						} else if icmd := chanCmd(cmpl, nameFunc, params); icmd != nil {
+							if icmd.ID == core.StackSend && !isEqualTypes(params[0].IndexOf, params[1]) {
+								return cmpl.ErrorFunction(ErrFunction, prevToken.Pos-1, nameFunc, params)
+							}
+							icmd.TokenID = uint32(prevToken.Pos - 1)
+							for i := prevToken.LenExp; i < len(cmpl.exp); i++ {
+								icmd.Children = append(icmd.Children, cmpl.exp[i])
+							}
+							cmpl.exp = cmpl.exp[:len(cmpl.exp)-numParams]
+							cmpl.exp = append(cmpl.exp, icmd)
 						} else if nameFunc == `go` {
 							if numParams > 0 {
 								return cmpl.ErrorPos(prevToken.Pos-1, ErrGoParam)
@@ -528,10 +594,34 @@ func appendExpBuf(cmpl *compiler, operation int) error {
 								fnVar  core.ICmd
 							)
 							obj := getFunc(cmpl, nameFunc, params)
+							if obj == nil {
+								obj = getFnFunc(cmpl, nameFunc, params,
+									cmpl.exp[prevToken.LenExp:prevToken.LenExp+numParams], 0)
+							}
+							if obj == nil {
+								obj = getMapArr(cmpl, nameFunc, params)
+							}
+							if obj == nil {
+								if obj = getIfaceFunc(cmpl, nameFunc, params, 0); obj != nil {
+									for i, par := range obj.GetParams() {
+										if i < numParams && par.Iface != nil && par != params[i] {
+											cmpl.exp[prevToken.LenExp+i] = toIface(cmpl, par,
+												cmpl.exp[prevToken.LenExp+i])
+										}
+									}
+								}
+							}
+							if obj == nil {
+								var err error
+								if obj, err = getGeneric(cmpl, nameFunc, params); err != nil {
+									return err
+								}
+							}
 							if obj == nil {
 								var isMatch bool
-								block, ind := findVar(cmpl, nameFunc)
-								if block != nil {
+								if fnVar, result = getMethod(cmpl, nameFunc, params); fnVar != nil {
+									isMatch = true
+								} else if block, ind := findVar(cmpl, nameFunc); block != nil {
 									fnVar = &core.CmdVar{Block: block, Index: ind,
 										CmdCommon: core.CmdCommon{TokenID: uint32(cmpl.pos - 1)}}
 									if typeVar := fnVar.GetResult(); typeVar.Func != nil {
@@ -579,6 +669,15 @@ func appendExpBuf(cmpl *compiler, operation int) error {
 										result = params[0]
 									}
 								}
+								if obj.GetType() == core.ObjEmbedded {
+									fnResult, err := checkCallback(cmpl, nameFunc, params, prevToken.Pos-1)
+									if err != nil {
+										return err
+									}
+									if fnResult != nil {
+										result = fnResult
+									}
+								}
 								pobj = obj
 							}
 							if optCount > 0 {
@@ -606,6 +705,12 @@ func appendExpBuf(cmpl *compiler, operation int) error {
 							for i := prevToken.LenExp; i < len(cmpl.exp); i++ {
 								icmd.Children = append(icmd.Children, cmpl.exp[i])
 							}
+							if pobj != nil && (pobj.GetName() == core.DefMapArr ||
+								pobj.GetName() == core.DefParallelMapArr) {
+								// the new array for the result of Map
+								icmd.Children = append(icmd.Children, &core.CmdBlock{ID: core.StackNew,
+									Result: result, CmdCommon: icmd.CmdCommon})
+							}
 							cmpl.exp = cmpl.exp[:len(cmpl.exp)-numParams-optCount]
 							cmpl.exp = append(cmpl.exp, icmd)
 						}
@@ -657,7 +762,7 @@ func setIndex(cmpl *compiler) error {
 	if typeObject.Original == reflect.TypeOf(core.Map{}) {
 		varIndex = cmpl.getStrType()
 	}
-	if typeObject.IndexOf == nil {
+	if typeObject.IndexOf == nil || typeObject.Original == reflect.TypeOf(core.Chan{}) {
 		return cmpl.ErrorPos(cmpl.expbuf[len(cmpl.expbuf)-1].Pos-1, ErrSupportIndex,
 			typeObject.GetName())
 	}
@@ -738,6 +843,18 @@ func coComma(cmpl *compiler) error {
 	if isInState(cmpl, cmInit, 1) || isInState(cmpl, cmInit, 2) {
 		return nil
 	}
+	if len(cmpl.expbuf) == 0 && len(cmpl.exp) > 0 {
+		// the list of the results or the variables for the destructuring assignment
+		if cmpl.curOwner().ID == core.StackReturn {
+			return nil
+		}
+		for _, item := range cmpl.exp {
+			if item.GetType() != core.CtVar {
+				return cmpl.Error(ErrOper)
+			}
+		}
+		return nil
+	}
 	if len(cmpl.expbuf) < 2 || (cmpl.expbuf[len(cmpl.expbuf)-1].Oper != tkLPar &&
 		cmpl.expbuf[len(cmpl.expbuf)-2].Oper != tkCallFunc) {
 		return cmpl.Error(ErrOper)
@@ -748,3 +865,33 @@ func coComma(cmpl *compiler) error {
 func coUnaryPostOperator(cmpl *compiler) error {
 	return appendExpBuf(cmpl, int(cmpl.unit.Lexeme.Tokens[cmpl.pos].Type)|tkUnary|tkPost)
 }
+
+// destructAssign assigns several results of the function to the variables
+func destructAssign(cmpl *compiler, pos int) error {
+	right := cmpl.exp[len(cmpl.exp)-1]
+	vars := cmpl.exp[:len(cmpl.exp)-1]
+	tuple := right.GetResult().Tuple
+	if len(tuple) != len(vars) {
+		count := len(tuple)
+		if count == 0 {
+			count = 1
+		}
+		return cmpl.ErrorPos(pos, ErrDestruct, count, len(vars))
+	}
+	for i, item := range vars {
+		if item.GetType() != core.CtVar {
+			return cmpl.ErrorPos(pos, ErrLValue)
+		}
+		if err := checkCaptured(cmpl, item, pos); err != nil {
+			return err
+		}
+		if !isEqualTypes(item.GetResult(), tuple[i]) {
+			return cmpl.ErrorPos(pos, ErrStructAssign, tuple[i].GetName(), item.GetResult().GetName())
+		}
+	}
+	icmd := &core.CmdBlock{ID: core.StackDestruct, Result: right.GetResult(),
+		CmdCommon: core.CmdCommon{TokenID: uint32(pos)},
+		Children:  append([]core.ICmd{right}, vars...)}
+	cmpl.exp = append(cmpl.exp[:0], icmd)
+	return nil
+}
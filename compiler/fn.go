@@ -6,11 +6,21 @@ package compiler
 
 import (
 	"reflect"
-	///	"strings"
 
 	"github.com/gentee/gentee/core"
 )
 
+type fnLit struct {
+	Func     *core.FuncObject
+	Outer    *core.CmdBlock   // the block where the function literal is defined
+	Type     *core.TypeObject // fn type of the function literal
+	Captured []core.ICmd      // captured variables of the enclosing function
+	Vars     []int            // indexes of captured variables in the function literal
+	CurType  *core.TypeObject
+	Pos      int
+	IsDefer  bool // the body of defer statement
+}
+
 func coFn(cmpl *compiler) error {
 	token, err := checkNewType(cmpl)
 	if err != nil {
@@ -44,3 +54,160 @@ func coFnType(cmpl *compiler) error {
 	cmpl.curType.Func.Params = append(cmpl.curType.Func.Params, obj.(*core.TypeObject))
 	return nil
 }
+
+func coFnLit(cmpl *compiler) error {
+	lit := fnLit{
+		Outer:   cmpl.curOwner(),
+		CurType: cmpl.curType,
+		Pos:     cmpl.pos,
+	}
+	lit.Func = cmpl.ws.Objects[newFunc(cmpl, goExpPush(cmpl))].(*core.FuncObject)
+	cmpl.fnLits = append(cmpl.fnLits, lit)
+	return nil
+}
+
+func coFnLitBack(cmpl *compiler) error {
+	lit := cmpl.fnLits[len(cmpl.fnLits)-1]
+	block := &lit.Func.Block
+	if block.Result != nil {
+		if len(block.Children) == 0 {
+			return cmpl.Error(ErrMustReturn)
+		}
+		last := block.Children[len(block.Children)-1]
+		if last.GetType() != core.CtStack ||
+			last.(*core.CmdBlock).ID != core.StackReturn {
+			return cmpl.Error(ErrMustReturn)
+		}
+	}
+	cmpl.fnLits = cmpl.fnLits[:len(cmpl.fnLits)-1]
+	goExpPop(cmpl)
+	cmpl.owners = cmpl.owners[:len(cmpl.owners)-1]
+	cmpl.curType = lit.CurType
+
+	// the function literal is an operand of the expression
+	(*cmpl.states)[len(*cmpl.states)-1].Origin = &cmState{tkToken, cmExpOper, nil, nil, cfStopBack}
+	cmpl.dynamic = &cmState{tkToken, cmExpOper, nil, nil, 0}
+	appendExp(cmpl, litValue(&lit))
+	return nil
+}
+
+// litValue returns the command which creates fn value of the function literal
+func litValue(lit *fnLit) core.ICmd {
+	if len(lit.Captured) == 0 {
+		return &core.CmdValue{Value: &core.Fn{Func: lit.Func},
+			CmdCommon: core.CmdCommon{TokenID: uint32(lit.Pos)},
+			Result:    lit.Type}
+	}
+	return &core.CmdAnyFunc{CmdCommon: core.CmdCommon{TokenID: uint32(lit.Pos)},
+		Children: lit.Captured, Optional: lit.Vars,
+		Object: lit.Func, IsClosure: true, Result: lit.Type}
+}
+
+func coFnLitResult(cmpl *compiler) error {
+	obj, err := getType(cmpl)
+	if err != nil {
+		return err
+	}
+	cmpl.latestFunc().Block.Result = obj.(*core.TypeObject)
+	return coFnLitStart(cmpl)
+}
+
+func coFnLitStart(cmpl *compiler) error {
+	block := &cmpl.latestFunc().Block
+	if block.Variadic {
+		return cmpl.Error(ErrFnVariadic)
+	}
+	block.ParCount = len(block.Vars)
+	fnType := &core.TypeObject{Original: reflect.TypeOf(core.Fn{}), Func: &core.FnType{
+		Params: block.Vars, Result: block.Result}}
+	lit := &cmpl.fnLits[len(cmpl.fnLits)-1]
+	// the latest declared fn type with the same parameters and result is used
+	for i := len(cmpl.ws.Objects) - 1; i >= 0; i-- {
+		obj := cmpl.ws.Objects[i]
+		if obj.GetType() != core.ObjType || obj.(*core.TypeObject).Func == nil {
+			continue
+		}
+		if isEqualTypes(obj.(*core.TypeObject), fnType) && cmpl.unit.FindType(obj.GetName()) == obj {
+			lit.Type = obj.(*core.TypeObject)
+			return nil
+		}
+	}
+	// the anonymous fn type is used if there is no declared fn type
+	fnType.Name = `fn`
+	fnType.Unit = cmpl.unit
+	lit.Type = fnType
+	return nil
+}
+
+// checkCaptured is called when the variable is changed. If it is the captured variable of int,
+// bool, char, float or str type then the variable of the enclosing function is boxed so that
+// the function literals share it. The parameters and the loop variables cannot be boxed.
+func checkCaptured(cmpl *compiler, cmd core.ICmd, pos int) error {
+	cmdVar := cmd.(*core.CmdVar)
+	block, ind := cmdVar.Block, cmdVar.Index
+	captured := false
+	for i := len(cmpl.fnLits) - 1; i >= 0; i-- {
+		lit := cmpl.fnLits[i]
+		if block != &lit.Func.Block {
+			continue
+		}
+		j := -1
+		for k, v := range lit.Vars {
+			if v == ind {
+				j = k
+				break
+			}
+		}
+		if lit.IsDefer || j < 0 {
+			break
+		}
+		origin := lit.Captured[j].(*core.CmdVar)
+		block, ind = origin.Block, origin.Index
+		captured = true
+	}
+	if !captured {
+		return nil
+	}
+	switch block.Vars[ind].Original {
+	case reflect.TypeOf(int64(0)), reflect.TypeOf(true), reflect.TypeOf('a'),
+		reflect.TypeOf(float64(0.0)), reflect.TypeOf(``):
+	default:
+		return nil
+	}
+	canBox := block.ID != core.StackFor && ind >= block.ParCount
+	for _, i := range block.Optional {
+		canBox = canBox && i != ind
+	}
+	if !canBox {
+		for name, i := range cmdVar.Block.VarNames {
+			if i == cmdVar.Index {
+				return cmpl.ErrorPos(pos, ErrCaptured, name)
+			}
+		}
+	}
+	if block.Boxed == nil {
+		block.Boxed = make(map[int]bool)
+	}
+	block.Boxed[ind] = true
+	return nil
+}
+
+// captureVar adds the variable of the enclosing function to the captured variables
+// of the function literal
+func captureVar(cmpl *compiler, level int, token string) (*core.CmdBlock, int) {
+	lit := &cmpl.fnLits[level]
+	outer, ind := findBlockVar(cmpl, lit.Outer, level-1, token)
+	if outer == nil {
+		return nil, 0
+	}
+	block := &lit.Func.Block
+	if block.VarNames == nil {
+		block.VarNames = make(map[string]int)
+	}
+	block.VarNames[token] = len(block.Vars)
+	lit.Vars = append(lit.Vars, len(block.Vars))
+	lit.Captured = append(lit.Captured, &core.CmdVar{Block: outer, Index: ind,
+		CmdCommon: core.CmdCommon{TokenID: uint32(lit.Pos)}})
+	block.Vars = append(block.Vars, outer.Vars[ind])
+	return block, len(block.Vars) - 1
+}
@@ -81,6 +81,47 @@ func coRetType(cmpl *compiler) error {
 	return coFuncStart(cmpl)
 }
 
+// coParamsResults checks if the parentheses are the list of the results
+func coParamsResults(cmpl *compiler) error {
+	lp := cmpl.unit.Lexeme
+	i := cmpl.pos - 1
+	for ; i > 0 && lp.Tokens[i].Type == tkLine; i-- {
+	}
+	if lp.Tokens[i].Type == tkRPar {
+		cmpl.latestFunc().Block.Result = &core.TypeObject{}
+		cmpl.dynamic = &cmState{tkLPar, cmResult, nil, nil, 0}
+	}
+	return nil
+}
+
+func coResultType(cmpl *compiler) error {
+	obj, err := getType(cmpl)
+	if err != nil {
+		return err
+	}
+	result := cmpl.latestFunc().Block.Result
+	result.Tuple = append(result.Tuple, obj.(*core.TypeObject))
+	return nil
+}
+
+// coResults defines the type of several results of the function
+func coResults(cmpl *compiler) error {
+	block := &cmpl.latestFunc().Block
+	result := block.Result
+	if len(result.Tuple) == 1 {
+		block.Result = result.Tuple[0]
+		return coFuncStart(cmpl)
+	}
+	names := make([]string, len(result.Tuple))
+	for i, item := range result.Tuple {
+		names[i] = item.GetName()
+	}
+	result.Name = `(` + strings.Join(names, `, `) + `)`
+	result.Unit = cmpl.unit
+	result.ObjID = int32(cmpl.appendObj(result))
+	return coFuncStart(cmpl)
+}
+
 func coFuncStart(cmpl *compiler) error {
 	funcObj := cmpl.latestFunc()
 	funcObj.Block.ParCount = len(funcObj.Block.Vars)
@@ -147,6 +188,162 @@ func getFunc(cmpl *compiler, name string, params []*core.TypeObject) (obj core.I
 	return nil
 }
 
+// isFnLit returns true if the command is the function literal
+func isFnLit(cmd core.ICmd) bool {
+	switch v := cmd.(type) {
+	case *core.CmdAnyFunc:
+		return v.IsClosure
+	case *core.CmdValue:
+		if fn, ok := v.Value.(*core.Fn); ok {
+			return strings.HasPrefix(fn.Func.GetName(), `*`)
+		}
+	}
+	return false
+}
+
+// getFnFunc looks for the function when function literals in parameters can have other
+// fn types with the same parameters and result
+func getFnFunc(cmpl *compiler, name string, params []*core.TypeObject, args []core.ICmd,
+	start int) core.IObject {
+	for i := start; i < len(params); i++ {
+		if !isFnLit(args[i]) {
+			continue
+		}
+		for _, item := range cmpl.ws.Objects {
+			if item.GetType() != core.ObjType || item == params[i] ||
+				item.(*core.TypeObject).Func == nil || !isEqualTypes(item.(*core.TypeObject), params[i]) ||
+				cmpl.unit.FindType(item.GetName()) != item {
+				continue
+			}
+			fnParams := append([]*core.TypeObject{}, params...)
+			fnParams[i] = item.(*core.TypeObject)
+			if obj := getFunc(cmpl, name, fnParams); obj != nil {
+				return obj
+			}
+			if obj := getFnFunc(cmpl, name, fnParams, args, i+1); obj != nil {
+				return obj
+			}
+		}
+	}
+	return nil
+}
+
+// fnCallbacks describes fn parameters of the higher-order functions. The last type is the
+// result. T is the type of items, A is the type of the third parameter, K is int, char, float
+// or str, R is any type and - means no result. The callbacks of maps get the key before the item.
+var fnCallbacks = map[string]string{
+	`After`:       `-`,
+	`All`:         `T bool`,
+	`Any`:         `T bool`,
+	`Every`:       `-`,
+	`Filter`:      `T bool`,
+	`FindIndex`:   `T bool`,
+	`GroupBy`:     `T str`,
+	`Map`:         `T R`,
+	`OnSignal`:    `bool`,
+	`ParallelFor`: `T -`,
+	`ParallelMap`: `T R`,
+	`Reduce`:      `A T A`,
+	`Schedule`:    `-`,
+	`Sort`:        `T T bool`,
+	`SortBy`:      `T K`,
+	`SortStable`:  `T T bool`,
+	`Task`:        `-`,
+}
+
+// getMapArr returns the embedded function for Map or ParallelMap of the array. It gets the result
+// array as the additional parameter because the type of the result depends on fn.
+func getMapArr(cmpl *compiler, name string, params []*core.TypeObject) core.IObject {
+	if len(params) < 2 || params[0].Original != reflect.TypeOf(core.Array{}) ||
+		params[len(params)-1].Func == nil {
+		return nil
+	}
+	switch {
+	case name == `Map` && len(params) == 2:
+		return cmpl.ws.StdLib().FindObj(core.DefMapArr)
+	case name == `ParallelMap` && len(params) == 3 &&
+		params[1].Original == reflect.TypeOf(int64(0)):
+		return cmpl.ws.StdLib().FindObj(core.DefParallelMapArr)
+	}
+	return nil
+}
+
+// checkCallback checks fn parameter of the higher-order function and returns the type
+// of the result if it depends on fn. fn is the second parameter or the third one if the second
+// parameter is the count of workers.
+func checkCallback(cmpl *compiler, name string, params []*core.TypeObject,
+	pos int) (*core.TypeObject, error) {
+	index := 1
+	if len(params) > 2 && params[1].Func == nil && params[2].Func != nil {
+		index = 2
+	}
+	pattern, ok := fnCallbacks[name]
+	if !ok || len(params) <= index || params[index].Func == nil {
+		return nil, nil
+	}
+	fnType := params[index].Func
+	isMap := params[0].Original == reflect.TypeOf(core.Map{})
+	stdType := func(name string) *core.TypeObject {
+		return cmpl.ws.StdLib().FindType(name).(*core.TypeObject)
+	}
+	types := strings.Fields(pattern)
+	var fnParams []*core.TypeObject
+	for _, item := range types[:len(types)-1] {
+		switch item {
+		case `T`:
+			if isMap {
+				fnParams = append(fnParams, stdType(`str`))
+			}
+			fnParams = append(fnParams, params[0].IndexOf)
+		case `A`:
+			fnParams = append(fnParams, params[2])
+		}
+	}
+	ok = (fnType.Result != nil) == (types[len(types)-1] != `-`) &&
+		len(fnType.Params) == len(fnParams)
+	for i := 0; ok && i < len(fnParams); i++ {
+		ok = isEqualTypes(fnType.Params[i], fnParams[i])
+	}
+	if ok {
+		switch types[len(types)-1] {
+		case `A`:
+			ok = isEqualTypes(fnType.Result, params[2])
+		case `K`:
+			switch fnType.Result.Original {
+			case reflect.TypeOf(int64(0)), reflect.TypeOf('a'), reflect.TypeOf(float64(0.0)),
+				reflect.TypeOf(``):
+			default:
+				ok = false
+			}
+		case `R`, `-`:
+		default:
+			ok = fnType.Result == stdType(types[len(types)-1])
+		}
+	}
+	if !ok {
+		return nil, cmpl.ErrorFunction(ErrFnCallback, pos, name, params)
+	}
+	switch name {
+	case `Map`, `ParallelMap`:
+		prefix := `arr.`
+		if isMap {
+			prefix = `map.`
+		}
+		result, err := autoType(cmpl, prefix+fnType.Result.GetName())
+		if err != nil {
+			return nil, err
+		}
+		return result.(*core.TypeObject), nil
+	case `GroupBy`:
+		result, err := autoType(cmpl, `map.`+params[0].GetName())
+		if err != nil {
+			return nil, err
+		}
+		return result.(*core.TypeObject), nil
+	}
+	return nil, nil
+}
+
 func getOperator(cmpl *compiler, name string, left, right core.ICmd) (obj core.IObject) {
 	params := []*core.TypeObject{left.GetResult()}
 	if right != nil {
@@ -179,6 +376,30 @@ func coFuncName(cmpl *compiler) error {
 	if strings.IndexRune(token, '.') >= 0 {
 		return cmpl.Error(ErrIdent)
 	}
+	if generic, err := coGeneric(cmpl, token); generic || err != nil {
+		return err
+	}
 	newFunc(cmpl, token)
 	return nil
 }
+
+// thisName is the name of the struct parameter of the method
+const thisName = `this`
+
+// coMethodName makes the method of the struct type from the function. The struct is
+// the first parameter of the method.
+func coMethodName(cmpl *compiler) error {
+	funcObj := cmpl.latestFunc()
+	obj := cmpl.unit.FindType(funcObj.Name)
+	if obj == nil || obj.(*core.TypeObject).Custom == nil {
+		return cmpl.ErrorPos(int(funcObj.Block.TokenID), ErrMethod, funcObj.Name)
+	}
+	token := getToken(cmpl.unit.Lexeme, cmpl.pos)
+	if isCapital(token) {
+		return cmpl.Error(ErrCapitalLetters)
+	}
+	funcObj.Name = token
+	funcObj.Block.VarNames = map[string]int{thisName: 0}
+	funcObj.Block.Vars = []*core.TypeObject{obj.(*core.TypeObject)}
+	return nil
+}
@@ -0,0 +1,228 @@
+// Copyright 2019 Alexey Krivonogov. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package compiler
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/gentee/gentee/core"
+)
+
+// hasTypeParam returns true if the type name contains a type parameter like T, arr.T or map.T
+func hasTypeParam(name string) bool {
+	for _, item := range strings.Split(name, `.`) {
+		if len(item) > 0 && isCapital(item) {
+			return true
+		}
+	}
+	return false
+}
+
+func nextToken(lp *core.Lex, i int) int {
+	for ; i < len(lp.Tokens) && lp.Tokens[i].Type == tkLine; i++ {
+	}
+	return i
+}
+
+// genericHeader parses the parameters and the result of the function and returns
+// the index of the token which starts the body of the function
+func genericHeader(cmpl *compiler, generic *core.Generic) int {
+	lp := cmpl.unit.Lexeme
+	i := nextToken(lp, cmpl.pos+1)
+	if i < len(lp.Tokens) && lp.Tokens[i].Type == tkLPar {
+		var curType string
+		for i = nextToken(lp, i+1); i < len(lp.Tokens) && lp.Tokens[i].Type != tkRPar; i =
+			nextToken(lp, i+1) {
+			switch lp.Tokens[i].Type {
+			case tkIdent:
+				next := nextToken(lp, i+1)
+				if next < len(lp.Tokens) && lp.Tokens[next].Type == tkIdent {
+					curType = getToken(lp, i)
+				} else if len(curType) > 0 {
+					generic.Params = append(generic.Params, curType)
+				} else {
+					return 0
+				}
+			case tkComma:
+			default:
+				return 0
+			}
+		}
+		i = nextToken(lp, i+1)
+		if i < len(lp.Tokens) && lp.Tokens[i].Type == tkLPar {
+			// several results
+			for i = nextToken(lp, i+1); i < len(lp.Tokens) && lp.Tokens[i].Type != tkRPar; i =
+				nextToken(lp, i+1) {
+				switch lp.Tokens[i].Type {
+				case tkIdent:
+					generic.Results = append(generic.Results, getToken(lp, i))
+				case tkComma:
+				default:
+					return 0
+				}
+			}
+			i = nextToken(lp, i+1)
+		}
+	}
+	if len(generic.Results) == 0 && i < len(lp.Tokens) && lp.Tokens[i].Type == tkIdent {
+		generic.Results = append(generic.Results, getToken(lp, i))
+		i = nextToken(lp, i+1)
+	}
+	if i >= len(lp.Tokens) || (lp.Tokens[i].Type != tkLCurly && lp.Tokens[i].Type != tkColon) {
+		return 0
+	}
+	return i
+}
+
+// skipBody returns the index of the closing curly brace of the body. It converts colons
+// to blocks except colons in initialization lists.
+func skipBody(cmpl *compiler, start int) (int, error) {
+	var level, inits int
+	lp := cmpl.unit.Lexeme
+	for i := start; i < len(lp.Tokens); i++ {
+		switch lp.Tokens[i].Type {
+		case tkColon:
+			if inits > 0 {
+				continue
+			}
+			if err := colonToLine(cmpl, i); err != nil {
+				return 0, err
+			}
+			fallthrough
+		case tkLCurly:
+			if inits > 0 || (i > 0 && (lp.Tokens[i-1].Type == tkAssign ||
+				lp.Tokens[i-1].Type == tkBitAndEq)) {
+				inits++
+			}
+			level++
+		case tkRCurly:
+			if inits > 0 {
+				inits--
+			}
+			if level--; level == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, cmpl.ErrorPos(len(lp.Tokens), ErrEnd)
+}
+
+// coGeneric defines the generic function if its parameters have type parameters.
+// The body of the generic function is compiled for each new set of types of the parameters.
+func coGeneric(cmpl *compiler, name string) (bool, error) {
+	lp := cmpl.unit.Lexeme
+	if cmpl.typeParams != nil || (cmpl.pos+1 < len(lp.Tokens) && lp.Tokens[cmpl.pos+1].Type == tkDot) {
+		return false, nil
+	}
+	generic := &core.Generic{}
+	body := genericHeader(cmpl, generic)
+	if body == 0 {
+		return false, nil
+	}
+	typeParams := make(map[string]bool)
+	for _, par := range generic.Params {
+		if hasTypeParam(par) {
+			for _, item := range strings.Split(par, `.`) {
+				typeParams[item] = true
+			}
+		}
+	}
+	var results bool
+	for _, result := range generic.Results {
+		results = results || hasTypeParam(result)
+	}
+	if !results && len(typeParams) == 0 {
+		return false, nil
+	}
+	for _, result := range generic.Results {
+		for _, item := range strings.Split(result, `.`) {
+			if hasTypeParam(item) && !typeParams[item] {
+				return false, cmpl.Error(ErrTypeParam, item)
+			}
+		}
+	}
+	if cmpl.unit.FindGeneric(name) != nil {
+		return false, cmpl.Error(ErrFuncExists, name, ``)
+	}
+	for generic.Start = cmpl.pos; lp.Tokens[generic.Start].Type != tkFunc; generic.Start-- {
+	}
+	end, err := skipBody(cmpl, body)
+	if err != nil {
+		return false, err
+	}
+	generic.End = end
+	funcObj := &core.FuncObject{
+		Object: core.Object{
+			Name: name,
+			Unit: cmpl.unit,
+		},
+		Generic: generic,
+	}
+	ind := cmpl.appendObj(funcObj)
+	funcObj.ObjID = int32(ind)
+	cmpl.unit.AddGeneric(ind, funcObj, cmpl.unit.Pub != 0)
+	if cmpl.unit.Pub == core.PubOne {
+		cmpl.unit.Pub = 0
+	}
+	cmpl.newPos = end - 1
+	cmpl.dynamic = &cmState{tkRCurly, cmGeneric, nil, nil, 0}
+	return true, nil
+}
+
+// bindType checks if the type suits the type name of the parameter of the generic function
+// and defines the type parameters
+func bindType(cmpl *compiler, name string, typeObj *core.TypeObject) bool {
+	if isCapital(name) {
+		if bound, ok := cmpl.typeParams[name]; ok {
+			return bound == typeObj
+		}
+		cmpl.typeParams[name] = typeObj
+		return true
+	}
+	if hasTypeParam(name) {
+		ins := strings.SplitN(name, `.`, 2)
+		if len(ins) != 2 || typeObj.IndexOf == nil ||
+			(ins[0] == `arr` && typeObj.Original != reflect.TypeOf(core.Array{})) ||
+			(ins[0] == `map` && typeObj.Original != reflect.TypeOf(core.Map{})) ||
+			(ins[0] != `arr` && ins[0] != `map`) {
+			return false
+		}
+		return bindType(cmpl, ins[1], typeObj.IndexOf)
+	}
+	obj, err := autoType(cmpl, name)
+	return err == nil && obj == typeObj
+}
+
+// getGeneric returns the instance of the generic function for the types of the parameters.
+// The instance is compiled if it has not been created yet.
+func getGeneric(cmpl *compiler, name string, params []*core.TypeObject) (core.IObject, error) {
+	obj := cmpl.unit.FindGeneric(name)
+	if obj == nil {
+		return nil, nil
+	}
+	funcObj := obj.(*core.FuncObject)
+	if len(funcObj.Generic.Params) != len(params) {
+		return nil, nil
+	}
+	instance := newCompiler(cmpl.ws, funcObj.Unit)
+	instance.typeParams = make(map[string]*core.TypeObject)
+	for i, par := range funcObj.Generic.Params {
+		if params[i] == nil || !bindType(instance, par, params[i]) {
+			return nil, nil
+		}
+	}
+	if obj = getFunc(instance, name, params); obj != nil {
+		return obj, nil
+	}
+	pub := funcObj.Unit.Pub
+	funcObj.Unit.Pub = 0
+	err := instance.compileTokens(funcObj.Generic.Start, funcObj.Generic.End)
+	funcObj.Unit.Pub = pub
+	if err != nil {
+		return nil, err
+	}
+	return getFunc(instance, name, params), nil
+}
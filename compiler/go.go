@@ -37,6 +37,11 @@ func goExpPop(cmpl *compiler) {
 	cmpl.goStack = cmpl.goStack[:len(cmpl.goStack)-1]
 }
 
+// isGoBlock returns true if the current function is the body of go statement
+func isGoBlock(cmpl *compiler) bool {
+	return len(cmpl.goStack) > 0 && cmpl.latestFunc().Name == cmpl.goStack[len(cmpl.goStack)-1].Name
+}
+
 func coGo(cmpl *compiler) error {
 	newFunc(cmpl, goExpPush(cmpl))
 	return nil
@@ -0,0 +1,83 @@
+// Copyright 2019 Alexey Krivonogov. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package compiler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gentee/gentee/core"
+)
+
+// parseVersion converts the version like 1.8.0+2 to the list of numbers
+func parseVersion(version string) ([]int, bool) {
+	if off := strings.IndexAny(version, `+-`); off >= 0 {
+		version = version[:off]
+	}
+	var ret []int
+	for _, item := range strings.Split(strings.TrimPrefix(version, `v`), `.`) {
+		v, err := strconv.Atoi(item)
+		if err != nil || v < 0 {
+			return nil, false
+		}
+		ret = append(ret, v)
+	}
+	return ret, true
+}
+
+// compareVersion returns -1, 0 or 1 if the version is less, equal or greater than required
+func compareVersion(version, required []int) int {
+	for i := 0; i < len(version) || i < len(required); i++ {
+		var left, right int
+		if i < len(version) {
+			left = version[i]
+		}
+		if i < len(required) {
+			right = required[i]
+		}
+		if left != right {
+			if left < right {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// checkHeader checks the standard keys of the # header
+func (cmpl *compiler) checkHeader() error {
+	unit := cmpl.unit
+	if value := unit.GetHeader(`version`); len(value) > 0 {
+		required, ok := parseVersion(value)
+		if !ok {
+			return cmpl.Error(ErrHeader, `version`)
+		}
+		current, _ := parseVersion(core.Version)
+		if compareVersion(current, required) < 0 {
+			return cmpl.Error(ErrVersion, value)
+		}
+	}
+	for key, bitSize := range map[string]int{`cycle`: 64, `depth`: 32} {
+		if value := unit.GetHeader(key); len(value) > 0 {
+			if _, err := strconv.ParseUint(value, 10, bitSize); err != nil {
+				return cmpl.Error(ErrHeader, key)
+			}
+		}
+	}
+	return nil
+}
+
+// headerSettings assigns the limits and the required environment variables of the header
+func headerSettings(unit *core.Unit, exec *core.Exec) {
+	exec.Cycle, _ = strconv.ParseUint(unit.GetHeader(`cycle`), 10, 64)
+	depth, _ := strconv.ParseUint(unit.GetHeader(`depth`), 10, 32)
+	exec.Depth = uint32(depth)
+	for _, name := range strings.Split(unit.GetHeader(`env`), `,`) {
+		if name = strings.TrimSpace(name); len(name) > 0 {
+			exec.Env = append(exec.Env, name)
+		}
+	}
+}
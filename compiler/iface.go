@@ -0,0 +1,161 @@
+// Copyright 2019 Alexey Krivonogov. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package compiler
+
+import (
+	"reflect"
+
+	"github.com/gentee/gentee/core"
+)
+
+func coIface(cmpl *compiler) error {
+	token, err := checkNewType(cmpl)
+	if err != nil {
+		return err
+	}
+	pType := cmpl.unit.NewType(token, reflect.TypeOf(core.Iface{}), nil).(*core.TypeObject)
+	pType.Iface = &core.IfaceType{
+		Fields: make(map[string]*core.TypeObject),
+		Impl:   make(map[*core.TypeObject][]*core.FuncObject),
+	}
+	cmpl.curType = pType
+	return nil
+}
+
+func coIfaceField(cmpl *compiler) error {
+	token := getToken(cmpl.unit.Lexeme, cmpl.pos)
+	if _, ok := cmpl.curType.Iface.Fields[token]; ok {
+		return cmpl.Error(ErrStructField, token)
+	}
+	// the type of the field is the previous token
+	cmpl.pos--
+	obj, err := getType(cmpl)
+	cmpl.pos++
+	if err != nil {
+		return err
+	}
+	cmpl.curType.Iface.Fields[token] = obj.(*core.TypeObject)
+	return nil
+}
+
+func coIfaceMethod(cmpl *compiler) error {
+	iface := cmpl.curType.Iface
+	iface.Names = append(iface.Names, getToken(cmpl.unit.Lexeme, cmpl.pos-1))
+	iface.Methods = append(iface.Methods, &core.FnType{})
+	return nil
+}
+
+func coIfaceParam(cmpl *compiler) error {
+	obj, err := getType(cmpl)
+	if err != nil {
+		return err
+	}
+	method := cmpl.curType.Iface.Methods[len(cmpl.curType.Iface.Methods)-1]
+	method.Params = append(method.Params, obj.(*core.TypeObject))
+	return nil
+}
+
+func coIfaceResult(cmpl *compiler) error {
+	obj, err := getType(cmpl)
+	if err != nil {
+		return err
+	}
+	cmpl.curType.Iface.Methods[len(cmpl.curType.Iface.Methods)-1].Result = obj.(*core.TypeObject)
+	return nil
+}
+
+// implements returns true if the struct type has all fields and methods of the interface.
+// The methods of the struct are saved in the interface type.
+func implements(cmpl *compiler, structType, iface *core.TypeObject) bool {
+	if structType.Custom == nil {
+		return false
+	}
+	if _, ok := iface.Iface.Impl[structType]; ok {
+		return true
+	}
+	for name, fieldType := range iface.Iface.Fields {
+		ind, ok := structType.Custom.Fields[name]
+		if !ok || !isEqualTypes(structType.Custom.Types[ind], fieldType) {
+			return false
+		}
+	}
+	funcs := make([]*core.FuncObject, len(iface.Iface.Names))
+	for i, name := range iface.Iface.Names {
+		method := iface.Iface.Methods[i]
+		obj := getFunc(cmpl, name, append([]*core.TypeObject{structType}, method.Params...))
+		if obj == nil || obj.GetType() != core.ObjFunc || obj.(*core.FuncObject).Block.Variadic ||
+			!isEqualTypes(obj.Result(), method.Result) {
+			return false
+		}
+		funcs[i] = obj.(*core.FuncObject)
+	}
+	iface.Iface.Impl[structType] = funcs
+	return true
+}
+
+// toIface returns the command which converts the struct value to the interface or nil if
+// the struct doesn't implement the interface
+func toIface(cmpl *compiler, iface *core.TypeObject, cmd core.ICmd) core.ICmd {
+	if cmd.GetResult() == iface {
+		return cmd
+	}
+	if !implements(cmpl, cmd.GetResult(), iface) {
+		return nil
+	}
+	return &core.CmdBlock{ID: core.StackIface, Result: iface, Children: []core.ICmd{cmd},
+		CmdCommon: core.CmdCommon{TokenID: uint32(cmd.GetToken())}}
+}
+
+// getIfaceFunc looks for the function which has interface parameters instead of
+// the struct types of the arguments
+func getIfaceFunc(cmpl *compiler, name string, params []*core.TypeObject, start int) core.IObject {
+	for i := start; i < len(params); i++ {
+		if params[i] == nil || params[i].Custom == nil {
+			continue
+		}
+		for _, item := range cmpl.ws.Objects {
+			if item.GetType() != core.ObjType || item.(*core.TypeObject).Iface == nil ||
+				cmpl.unit.FindType(item.GetName()) != item ||
+				!implements(cmpl, params[i], item.(*core.TypeObject)) {
+				continue
+			}
+			ifaceParams := append([]*core.TypeObject{}, params...)
+			ifaceParams[i] = item.(*core.TypeObject)
+			if obj := getFunc(cmpl, name, ifaceParams); obj != nil {
+				return obj
+			}
+			if obj := getIfaceFunc(cmpl, name, ifaceParams, i+1); obj != nil {
+				return obj
+			}
+		}
+	}
+	return nil
+}
+
+// getMethod returns the command which gets the method of the interface value at runtime
+// and the type of the result of this method
+func getMethod(cmpl *compiler, name string, params []*core.TypeObject) (core.ICmd, *core.TypeObject) {
+	if len(params) == 0 || params[0].Iface == nil {
+		return nil, nil
+	}
+	iface := params[0].Iface
+	for i, method := range iface.Methods {
+		if iface.Names[i] != name || len(method.Params) != len(params)-1 {
+			continue
+		}
+		equal := true
+		for k, par := range method.Params {
+			if !isEqualTypes(par, params[k+1]) {
+				equal = false
+				break
+			}
+		}
+		if equal {
+			return &core.CmdBlock{ID: core.StackMethod, Result: params[0], ParCount: i,
+				CmdCommon: core.CmdCommon{TokenID: uint32(cmpl.pos - 1)}}, method.Result
+		}
+	}
+	return nil, nil
+}
@@ -68,34 +68,38 @@ const (
 
 var (
 	keywords = map[string]int{
-		`break`:    tkBreak,
-		`continue`: tkContinue,
-		`elif`:     tkElif,
-		`else`:     tkElse,
-		`false`:    tkFalse,
-		`for`:      tkFor,
-		`func`:     tkFunc,
-		`if`:       tkIf,
-		`in`:       tkIn,
-		`while`:    tkWhile,
-		`return`:   tkReturn,
-		`run`:      tkRun,
-		`true`:     tkTrue,
-		`const`:    tkConst,
-		`struct`:   tkStruct,
-		`switch`:   tkSwitch,
-		`case`:     tkCase,
-		`include`:  tkInclude,
-		`import`:   tkImport,
-		`pub`:      tkPub,
-		`fn`:       tkFn,
-		`go`:       tkGo,
-		`local`:    tkLocal,
-		`try`:      tkTry,
-		`catch`:    tkCatch,
-		`recover`:  tkRecover,
-		`retry`:    tkRetry,
-		`default`:  tkDefault,
+		`break`:     tkBreak,
+		`continue`:  tkContinue,
+		`elif`:      tkElif,
+		`else`:      tkElse,
+		`false`:     tkFalse,
+		`for`:       tkFor,
+		`func`:      tkFunc,
+		`if`:        tkIf,
+		`in`:        tkIn,
+		`while`:     tkWhile,
+		`return`:    tkReturn,
+		`run`:       tkRun,
+		`true`:      tkTrue,
+		`const`:     tkConst,
+		`struct`:    tkStruct,
+		`interface`: tkInterface,
+		`switch`:    tkSwitch,
+		`case`:      tkCase,
+		`include`:   tkInclude,
+		`import`:    tkImport,
+		`pub`:       tkPub,
+		`fn`:        tkFn,
+		`go`:        tkGo,
+		`local`:     tkLocal,
+		`try`:       tkTry,
+		`catch`:     tkCatch,
+		`recover`:   tkRecover,
+		`retry`:     tkRetry,
+		`default`:   tkDefault,
+		`finally`:   tkFinally,
+		`defer`:     tkDefer,
+		`select`:    tkSelect,
 	}
 
 	charType [alphabet]int
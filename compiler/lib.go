@@ -29,6 +29,20 @@ func isBaseResult(cmd core.ICmd) bool {
 	return false
 }
 
+func isTuple(cmd core.ICmd) bool {
+	return cmd.GetResult() != nil && cmd.GetResult().Tuple != nil
+}
+
+// checkTuple returns an error if any of the commands returns several values
+func checkTuple(cmpl *compiler, cmds ...core.ICmd) error {
+	for _, cmd := range cmds {
+		if isTuple(cmd) {
+			return cmpl.ErrorPos(cmd.GetToken(), ErrTupleValue)
+		}
+	}
+	return nil
+}
+
 func isCase(cmpl *compiler) bool {
 	parent := cmpl.owners[len(cmpl.owners)-1]
 	return parent.GetType() == core.CtStack && parent.(*core.CmdBlock).ID == core.StackCase
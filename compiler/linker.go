@@ -24,6 +24,12 @@ type BlockInfo struct {
 type Linker struct {
 	Blocks []BlockInfo
 	Lex    *core.Lex
+	Jumps  []int // offsets of JMP, JZE and JNZ commands
+	// Stored is the variable assigned by the latest statement, its value is on the top of the stack
+	Stored    *core.CmdVar
+	StoredEnd int // the offset of the end of the assignment
+	// NoOptimize disables constant folding, jump threading, INCVAR and the removing of loads
+	NoOptimize bool
 }
 
 // Int32Slice is a slice of int32
@@ -50,6 +56,7 @@ func Link(ws *core.Workspace, unitID int) (*core.Exec, error) {
 		Init:    bcode.Init,
 		Pos:     bcode.Pos,
 		Structs: bcode.StructsList,
+		Methods: bcode.Methods,
 		Path:    unit.Lexeme.Path,
 
 		CRCStdlib: vm.CRCStdlib,
@@ -58,6 +65,7 @@ func Link(ws *core.Workspace, unitID int) (*core.Exec, error) {
 	if len(exec.Path) == 0 {
 		exec.Path = unit.Name
 	}
+	headerSettings(unit, exec)
 	var (
 		ok  bool
 		ind uint16
@@ -145,6 +153,12 @@ func Link(ws *core.Workspace, unitID int) (*core.Exec, error) {
 }
 
 func copyUsed(src, dest *core.Bytecode) {
+	for key, id := range src.Methods {
+		if dest.Methods == nil {
+			dest.Methods = make(map[int64]int32)
+		}
+		dest.Methods[key] = id
+	}
 	if src.Used == nil {
 		return
 	}
@@ -156,6 +170,18 @@ func copyUsed(src, dest *core.Bytecode) {
 	}
 }
 
+// useFunc appends the function to the used functions of the bytecode
+func useFunc(funcObj *core.FuncObject, out *core.Bytecode) {
+	if out.Used == nil {
+		out.Used = make(map[int32]byte)
+	}
+	if out.Used[funcObj.ObjID] == 0 {
+		genBytecode(funcObj.Unit.VM, funcObj.ObjID)
+		copyUsed(&funcObj.BCode, out)
+		out.Used[funcObj.ObjID] = 1
+	}
+}
+
 func structOffset(out *core.Bytecode, shift int) {
 	out.StructsOffset = append(out.StructsOffset, int32(shift))
 }
@@ -192,6 +218,9 @@ func initBlock(linker *Linker, cmd *core.CmdBlock, out *core.Bytecode) (BlockInf
 	if flags&core.BlRetry != 0 {
 		push(0)
 	}
+	if flags&core.BlFinally != 0 {
+		push(0)
+	}
 	if cmd.ParCount > 0 || flags&core.BlVars != 0 {
 		push(core.Bcode(cmd.ParCount<<16 | len(cmd.Vars)))
 	}
@@ -201,8 +230,8 @@ func initBlock(linker *Linker, cmd *core.CmdBlock, out *core.Bytecode) (BlockInf
 		var sInt, sStr, sFloat, sAny int
 		bInfo.Vars = make([]int, len(cmd.Vars))
 		lenCode := len(out.Code)
-		for i, ivar := range cmd.Vars {
-			types[i] = type2Code(ivar, out)
+		for i := range cmd.Vars {
+			types[i] = varCode(cmd, i, out)
 			switch types[i] & 0xf {
 			case core.STACKSTR:
 				bInfo.Vars[i] = sStr
@@ -222,11 +251,24 @@ func initBlock(linker *Linker, cmd *core.CmdBlock, out *core.Bytecode) (BlockInf
 			}
 		}
 		push(types...)
+		for i := range cmd.Vars {
+			if cmd.Boxed[i] {
+				push(core.Bcode(bInfo.Vars[i]<<16)|core.BOX, type2Code(cmd.Vars[i], out))
+			}
+		}
 	}
 	linker.Blocks = append(linker.Blocks, bInfo)
 	return bInfo, types
 }
 
+// varCode returns the type code of the variable. The boxed variable is an array with one item.
+func varCode(block *core.CmdBlock, ind int, out *core.Bytecode) core.Bcode {
+	if _, ok := block.Boxed[ind]; ok {
+		return core.TYPEARR
+	}
+	return type2Code(block.Vars[ind], out)
+}
+
 func type2Code(itype *core.TypeObject, out *core.Bytecode) (retType core.Bcode) {
 	switch itype.Original {
 	case reflect.TypeOf(int64(0)):
@@ -241,10 +283,20 @@ func type2Code(itype *core.TypeObject, out *core.Bytecode) (retType core.Bcode)
 		retType = core.TYPESTR
 	case reflect.TypeOf(core.Array{}):
 		retType = core.TYPEARR
+		if itype.IndexOf != nil {
+			switch itype.IndexOf.Original {
+			case reflect.TypeOf(int64(0)):
+				retType = core.TYPEARRINT
+			case reflect.TypeOf(float64(0.0)):
+				retType = core.TYPEARRFLOAT
+			}
+		}
 	case reflect.TypeOf(core.Range{}):
 		retType = core.TYPERANGE
 	case reflect.TypeOf(core.Map{}):
 		retType = core.TYPEMAP
+	case reflect.TypeOf(core.Chan{}):
+		retType = core.TYPECHAN
 	case reflect.TypeOf(core.Buffer{}):
 		retType = core.TYPEBUF
 	case reflect.TypeOf(core.Fn{}):
@@ -255,6 +307,8 @@ func type2Code(itype *core.TypeObject, out *core.Bytecode) (retType core.Bcode)
 		retType = core.TYPESET
 	case reflect.TypeOf(core.Obj{}):
 		retType = core.TYPEOBJ
+	case reflect.TypeOf(core.Iface{}):
+		retType = core.TYPEIFACE
 	case reflect.TypeOf(core.Struct{}):
 		typeName := itype.GetName()
 		var (
@@ -263,6 +317,7 @@ func type2Code(itype *core.TypeObject, out *core.Bytecode) (retType core.Bcode)
 		)
 		if ind, ok = out.Structs[typeName]; !ok {
 			sInfo := core.StructInfo{
+				ID:     itype.ObjID,
 				Name:   typeName,
 				Fields: make([]uint16, len(itype.Custom.Types)),
 				Keys:   make([]string, len(itype.Custom.Types)),
@@ -299,6 +354,66 @@ func type2Code(itype *core.TypeObject, out *core.Bytecode) (retType core.Bcode)
 	return retType
 }
 
+// tailCall checks if the function call in return statement can reuse the frame of the
+// current function. It returns the count of int, float, str and any parameters.
+func tailCall(linker *Linker, cmd core.ICmd, out *core.Bytecode) (core.Bcode, bool) {
+	if cmd.GetType() != core.CtFunc {
+		return 0, false
+	}
+	anyFunc := cmd.(*core.CmdAnyFunc)
+	obj := anyFunc.GetObject()
+	if obj == nil || obj.GetType() != core.ObjFunc || anyFunc.IsThread || anyFunc.IsClosure {
+		return 0, false
+	}
+	for i := len(linker.Blocks) - 1; i >= 0; i-- {
+		if linker.Blocks[i].IsLocal {
+			break
+		}
+		// errors inside try block must be handled by the current function and
+		// finally code and deferred calls must be run after the call
+		block := linker.Blocks[i].Block
+		if _, ok := block.VarNames[deferVar]; ok {
+			return 0, false
+		}
+		if block.Parent != nil && block.Parent.ID == core.StackTry &&
+			(block.Parent.Children[0] == block || isFinally(block.Parent)) {
+			return 0, false
+		}
+		if block.Parent != nil && block.Parent.ID == core.StackCatch && isFinally(block.Parent.Parent) {
+			return 0, false
+		}
+	}
+	block := obj.(*core.FuncObject).Block
+	count := len(anyFunc.Children) - len(anyFunc.Optional)
+	if block.Variadic {
+		count = block.ParCount + 1
+	}
+	counts := stackCounts(block.Vars[:count], out)
+	return counts, counts != 0 || count == 0
+}
+
+// stackCounts returns the counts of int, float, str and any values of the types.
+// It returns 0 if the count is greater than 255
+func stackCounts(types []*core.TypeObject, out *core.Bytecode) core.Bcode {
+	var sInt, sFloat, sStr, sAny core.Bcode
+	for _, item := range types {
+		switch type2Code(item, out) & 0xf {
+		case core.STACKFLOAT:
+			sFloat++
+		case core.STACKSTR:
+			sStr++
+		case core.STACKANY:
+			sAny++
+		default:
+			sInt++
+		}
+	}
+	if sInt > 0xff || sFloat > 0xff || sStr > 0xff || sAny > 0xff {
+		return 0
+	}
+	return sInt<<24 | sFloat<<16 | sStr<<8 | sAny
+}
+
 func getPos(linker *Linker, cmd core.ICmd, out *core.Bytecode) {
 	var (
 		ok   bool
@@ -351,7 +466,9 @@ func genBytecode(ws *core.Workspace, idObj int32) *core.Bytecode {
 	type2Code(ws.StdLib().FindType(`time`).(*core.TypeObject), bcode)
 	type2Code(ws.StdLib().FindType(`finfo`).(*core.TypeObject), bcode)
 
-	cmd2Code(&Linker{Lex: ws.Objects[idObj].GetLex()}, block, bcode)
+	linker := &Linker{Lex: ws.Objects[idObj].GetLex(), NoOptimize: ws.NoOptimize}
+	cmd2Code(linker, block, bcode)
+	threadJumps(linker, bcode)
 	if isConst {
 		resType := type2Code(block.GetResult(), bcode)
 		bcode.Code = append(bcode.Code, (resType<<16)|core.RET)
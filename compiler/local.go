@@ -16,6 +16,10 @@ func getLocalBlock(cmpl *compiler) *core.CmdBlock {
 		if block.ID == core.StackBlock && block.Parent != nil && block.Parent.ID == core.StackLocal {
 			return cmpl.owners[i].(*core.CmdBlock)
 		}
+		if block.Object != nil {
+			// the body of the function literal
+			break
+		}
 	}
 	return nil
 }
@@ -0,0 +1,329 @@
+// Copyright 2019 Alexey Krivonogov. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package compiler
+
+import (
+	"github.com/gentee/gentee/core"
+)
+
+const (
+	// maxThreading is the maximum length of the chain of jumps
+	maxThreading = 8
+)
+
+func constInt(v interface{}) (int64, bool) {
+	switch val := v.(type) {
+	case int64:
+		return val, true
+	case rune:
+		return int64(val), true
+	case bool:
+		if val {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func bool2Int(v bool) int64 {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+// embedCode returns the bytecode of the embedded function
+func embedCode(cmd core.ICmd) core.Bcode {
+	obj := cmd.GetObject()
+	if obj == nil || obj.GetType() != core.ObjEmbedded {
+		return core.NOP
+	}
+	embed := obj.(*core.EmbedObject)
+	if _, ok := embed.Func.(int32); ok || embed.BCode.Code == nil {
+		return core.NOP
+	}
+	return embed.BCode.Code[0]
+}
+
+// constValue returns the value of the expression if it can be calculated at compile time.
+// Only pure operations of the standard library are calculated.
+func constValue(cmd core.ICmd) (interface{}, bool) {
+	var (
+		left, right interface{}
+		ok          bool
+	)
+	switch cmd.GetType() {
+	case core.CtValue:
+		switch v := cmd.(*core.CmdValue).Value.(type) {
+		case int64, float64, bool, rune, string:
+			return v, true
+		}
+		return nil, false
+	case core.CtUnary:
+		code := embedCode(cmd)
+		if code == core.NOP {
+			return nil, false
+		}
+		if left, ok = constValue(cmd.(*core.CmdUnary).Operand); !ok {
+			return nil, false
+		}
+		if code == core.SIGNFLOAT {
+			if v, ok := left.(float64); ok {
+				return -v, true
+			}
+			return nil, false
+		}
+		i, ok := constInt(left)
+		if !ok {
+			return nil, false
+		}
+		switch code {
+		case core.SIGN:
+			return -i, true
+		case core.BITNOT:
+			return ^i, true
+		case core.NOT:
+			return i == 0, true
+		}
+	case core.CtBinary:
+		code := embedCode(cmd)
+		if code == core.NOP {
+			return nil, false
+		}
+		if left, ok = constValue(cmd.(*core.CmdBinary).Left); !ok {
+			return nil, false
+		}
+		if right, ok = constValue(cmd.(*core.CmdBinary).Right); !ok {
+			return nil, false
+		}
+		switch code {
+		case core.ADDSTR, core.EQSTR, core.LTSTR, core.GTSTR:
+			l, lok := left.(string)
+			r, rok := right.(string)
+			if !lok || !rok {
+				return nil, false
+			}
+			switch code {
+			case core.ADDSTR:
+				return l + r, true
+			case core.EQSTR:
+				return l == r, true
+			case core.LTSTR:
+				return l < r, true
+			default:
+				return l > r, true
+			}
+		case core.ADDFLOAT, core.SUBFLOAT, core.MULFLOAT, core.EQFLOAT, core.LTFLOAT,
+			core.GTFLOAT:
+			l, lok := left.(float64)
+			r, rok := right.(float64)
+			if !lok || !rok {
+				return nil, false
+			}
+			switch code {
+			case core.ADDFLOAT:
+				return l + r, true
+			case core.SUBFLOAT:
+				return l - r, true
+			case core.MULFLOAT:
+				return l * r, true
+			case core.EQFLOAT:
+				return l == r, true
+			case core.LTFLOAT:
+				return l < r, true
+			default:
+				return l > r, true
+			}
+		}
+		l, lok := constInt(left)
+		r, rok := constInt(right)
+		if !lok || !rok {
+			return nil, false
+		}
+		// DIV, MOD and shifts are not calculated if they can generate a runtime error
+		switch code {
+		case core.ADD:
+			return l + r, true
+		case core.SUB:
+			return l - r, true
+		case core.MUL:
+			return l * r, true
+		case core.DIV:
+			if r != 0 {
+				return l / r, true
+			}
+		case core.MOD:
+			if r != 0 {
+				return l % r, true
+			}
+		case core.BITOR:
+			return l | r, true
+		case core.BITXOR:
+			return l ^ r, true
+		case core.BITAND:
+			return l & r, true
+		case core.LSHIFT:
+			if r >= 0 {
+				return l << uint32(r), true
+			}
+		case core.RSHIFT:
+			if r >= 0 {
+				return l >> uint32(r), true
+			}
+		case core.EQ:
+			return l == r, true
+		case core.LT:
+			return l < r, true
+		case core.GT:
+			return l > r, true
+		}
+	}
+	return nil, false
+}
+
+// foldConst replaces the expression with its value if it is possible
+func foldConst(linker *Linker, cmd core.ICmd) (core.ICmd, bool) {
+	if linker.NoOptimize {
+		return cmd, false
+	}
+	value, ok := constValue(cmd)
+	if !ok {
+		return cmd, false
+	}
+	result := cmd.GetResult()
+	if _, isBool := value.(bool); isBool && result.GetName() != `bool` {
+		// comparisons of int values return int
+		value = bool2Int(value.(bool))
+	}
+	return &core.CmdValue{Value: value, Result: result,
+		CmdCommon: core.CmdCommon{TokenID: uint32(cmd.GetToken())}}, true
+}
+
+// constCondition returns the value of the condition if it is known at compile time
+func constCondition(linker *Linker, cmd core.ICmd) (bool, bool) {
+	if linker.NoOptimize {
+		return false, false
+	}
+	value, ok := constValue(cmd)
+	if !ok {
+		return false, false
+	}
+	i, ok := constInt(value)
+	return i != 0, ok
+}
+
+// incVar checks if the assignment can be replaced with INCVAR. It returns the variable,
+// the added value and post flag.
+func incVar(linker *Linker, cmdStack *core.CmdBlock, out *core.Bytecode) (*core.CmdVar, int64,
+	bool, bool) {
+	cmdVar, ok := cmdStack.Children[0].(*core.CmdVar)
+	if !ok || linker.NoOptimize || len(cmdVar.Indexes) > 0 ||
+		varCode(cmdVar.Block, cmdVar.Index, out) != core.TYPEINT {
+		return nil, 0, false, false
+	}
+	if cmdStack.ID == core.StackIncDec {
+		value := int64(cmdStack.ParCount)
+		if value&1 == 0 {
+			return cmdVar, value / 2, true, true
+		}
+		return cmdVar, value, false, true
+	}
+	var (
+		value interface{}
+		code  core.Bcode
+	)
+	right := cmdStack.Children[1]
+	switch cmdStack.GetObject().GetName() {
+	case `AssignAdd`:
+		code = core.ADD
+	case `AssignSub`:
+		code = core.SUB
+	case `Assign`:
+		// a = a + const, a = a - const
+		code = embedCode(right)
+		if right.GetType() != core.CtBinary || (code != core.ADD && code != core.SUB) {
+			return nil, 0, false, false
+		}
+		leftVar, ok := right.(*core.CmdBinary).Left.(*core.CmdVar)
+		if !ok || leftVar.Block != cmdVar.Block || leftVar.Index != cmdVar.Index ||
+			len(leftVar.Indexes) > 0 {
+			return nil, 0, false, false
+		}
+		right = right.(*core.CmdBinary).Right
+	default:
+		return nil, 0, false, false
+	}
+	if value, ok = constValue(right); !ok {
+		return nil, 0, false, false
+	}
+	i, ok := value.(int64)
+	if !ok || i > 0x7fffffff || i < -0x7fffffff {
+		return nil, 0, false, false
+	}
+	if code == core.SUB {
+		i = -i
+	}
+	return cmdVar, i, false, true
+}
+
+// storeVar remembers the variable if the statement assigns the value to it. The assignment
+// leaves the value on the top of the stack, so the next command can use it instead of
+// getting the variable again.
+func storeVar(linker *Linker, cmd core.ICmd, out *core.Bytecode) {
+	linker.Stored = nil
+	cmdStack, ok := cmd.(*core.CmdBlock)
+	if !ok || linker.NoOptimize || (cmdStack.ID != core.StackAssign &&
+		cmdStack.ID != core.StackInit) {
+		return
+	}
+	cmdVar := cmdStack.Children[0].(*core.CmdVar)
+	if len(cmdVar.Indexes) > 0 {
+		return
+	}
+	switch varCode(cmdVar.Block, cmdVar.Index, out) {
+	case core.TYPEINT, core.TYPEBOOL, core.TYPECHAR, core.TYPEFLOAT, core.TYPESTR:
+	default:
+		return
+	}
+	if cmdStack.ID == core.StackAssign {
+		if _, _, _, ok := incVar(linker, cmdStack, out); ok {
+			return
+		}
+	}
+	linker.Stored = cmdVar
+	linker.StoredEnd = len(out.Code)
+}
+
+// loadStored returns true if the value of the variable assigned by the previous statement
+// is on the top of the stack
+func loadStored(linker *Linker, cmdVar *core.CmdVar, out *core.Bytecode) bool {
+	stored := linker.Stored
+	linker.Stored = nil
+	return stored != nil && linker.StoredEnd == len(out.Code) && len(cmdVar.Indexes) == 0 &&
+		stored.Block == cmdVar.Block && stored.Index == cmdVar.Index
+}
+
+// threadJumps redirects jumps to the final destination if they point to JMP commands
+func threadJumps(linker *Linker, out *core.Bytecode) {
+	if linker.NoOptimize {
+		return
+	}
+	isJump := make(map[int]bool)
+	for _, pos := range linker.Jumps {
+		isJump[pos] = true
+	}
+	for _, pos := range linker.Jumps {
+		target := pos + int(int16(out.Code[pos+1]))
+		for k := 0; k < maxThreading && target != pos && isJump[target] &&
+			out.Code[target] == core.JMP; k++ {
+			target += int(int16(out.Code[target+1]))
+		}
+		shift := target - pos
+		if shift >= -0x8000 && shift <= 0x7fff {
+			out.Code[pos+1] = core.Bcode(shift)
+		}
+	}
+}
@@ -33,14 +33,43 @@ func coReturnBack(cmpl *compiler) error {
 			return cmpl.Error(ErrMustReturn)
 		}
 	case 1:
+		if block.Result == nil && block == &cmpl.latestFunc().Block && isGoBlock(cmpl) {
+			// the first return defines the type of the result of the thread
+			block.Result = owner.Children[0].GetResult()
+		}
 		if block.Result == nil {
 			return cmpl.Error(ErrReturn)
 		}
 		if !isEqualTypes(block.Result, owner.Children[0].GetResult()) {
-			return cmpl.Error(ErrReturnType)
+			var ret core.ICmd
+			if block.Result.Iface != nil {
+				ret = toIface(cmpl, block.Result, owner.Children[0])
+			}
+			if ret == nil {
+				return cmpl.Error(ErrReturnType)
+			}
+			owner.Children[0] = ret
 		}
 	default:
-		return cmpl.Error(ErrCompiler, `coReturn 1`)
+		if block.Result == nil {
+			return cmpl.Error(ErrReturn)
+		}
+		if len(block.Result.Tuple) != len(owner.Children) {
+			return cmpl.Error(ErrReturnType)
+		}
+		for i, item := range block.Result.Tuple {
+			if isEqualTypes(item, owner.Children[i].GetResult()) {
+				continue
+			}
+			var ret core.ICmd
+			if item.Iface != nil {
+				ret = toIface(cmpl, item, owner.Children[i])
+			}
+			if ret == nil {
+				return cmpl.Error(ErrReturnType)
+			}
+			owner.Children[i] = ret
+		}
 	}
 	cmpl.owners = cmpl.owners[:len(cmpl.owners)-1]
 	return nil
@@ -38,6 +38,11 @@ func InitStdlib(ws *core.Workspace) {
 	stdlib.NewConst(core.ConstCycle, int64(16000000), true)
 	stdlib.NewConst(core.ConstScript, ``, true)
 	stdlib.NewConst(core.ConstVersion, core.Version, false)
+	for i, name := range []string{`TH_QUEUE`, `TH_WORK`, `TH_PAUSED`, `TH_WAIT`, `TH_FINISHED`,
+		`TH_ERROR`, `TH_CLOSED`} {
+		// the statuses of threads
+		stdlib.NewConst(name, int64(vm.ThQueue+i), false)
+	}
 
 	src := `
 	pub	func Run(str cmd, str args...) {
@@ -101,6 +101,10 @@ const (
 	tkRecover
 	tkRetry
 	tkDefault
+	tkInterface
+	tkFinally
+	tkDefer
+	tkSelect
 	tkToken // is used for preCompileTable
 )
 
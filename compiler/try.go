@@ -22,11 +22,18 @@ func coTry(cmpl *compiler) error {
 func coTryBack(cmpl *compiler) error {
 	cmpl.owners = cmpl.owners[:len(cmpl.owners)-1]
 	cmd := cmpl.curOwner()
+	if cmd.ID == core.StackCatch {
+		cmpl.owners = cmpl.owners[:len(cmpl.owners)-1]
+		cmd = cmpl.curOwner()
+	}
 	if cmd.ID == core.StackTry {
-		if len(cmd.Children) == 1 {
+		switch {
+		case len(cmd.Children) == 1:
 			cmpl.dynamic = &cmState{tkLCurly, cmCatch, nil, nil, 0}
-		} else {
+		case isFinally(cmd):
 			cmpl.owners = cmpl.owners[:len(cmpl.owners)-1]
+		default:
+			cmpl.dynamic = &cmState{tkLCurly, cmCatchNext, nil, nil, 0}
 		}
 	}
 	return nil
@@ -37,6 +44,10 @@ func coCatch(cmpl *compiler) error {
 	if err := checkUsedName(cmpl, token); err != nil {
 		return err
 	}
+	if owner := cmpl.curOwner(); len(owner.Children) > 1 &&
+		owner.Children[len(owner.Children)-1].(*core.CmdBlock).ID != core.StackCatch {
+		return cmpl.Error(ErrCatchAll)
+	}
 	cmd := core.CmdBlock{ID: core.StackBlock, CmdCommon: core.CmdCommon{TokenID: uint32(cmpl.pos)},
 		ParCount: 1, Vars: []*core.TypeObject{cmpl.unit.FindType(`error`).(*core.TypeObject)},
 		VarNames: map[string]int{token: 0}}
@@ -45,17 +56,59 @@ func coCatch(cmpl *compiler) error {
 	return nil
 }
 
-func coCatchBack(cmpl *compiler) error {
+// coCatchFilter moves the catch block into StackCatch command which has the filter of errors
+func coCatchFilter(cmpl *compiler) error {
+	coExpStart(cmpl)
+	block := cmpl.curOwner()
 	cmpl.owners = cmpl.owners[:len(cmpl.owners)-1]
+	owner := cmpl.curOwner()
+	owner.Children = owner.Children[:len(owner.Children)-1]
+	cmd := core.CmdBlock{ID: core.StackCatch, CmdCommon: core.CmdCommon{TokenID: uint32(cmpl.pos)}}
+	appendCmd(cmpl, &cmd)
+	block.Parent = &cmd
+	cmd.Children = append(cmd.Children, block)
+	cmpl.owners = append(cmpl.owners, &cmd)
+	return nil
+}
+
+// coCatchBody checks the filter of errors and starts the body of the catch block
+func coCatchBody(cmpl *compiler) error {
+	cmd := cmpl.curOwner()
+	if cmd.ID != core.StackCatch {
+		return nil
+	}
+	if len(cmd.Children) != 2 {
+		return cmpl.Error(ErrCatchFilter)
+	}
+	filter := cmd.Children[1]
+	if filter.GetResult() == nil || (!isIntResult(filter) &&
+		filter.GetResult().GetName() != `range`) {
+		cmpl.pos = filter.GetToken()
+		return cmpl.Error(ErrCatchFilter)
+	}
+	cmpl.owners = append(cmpl.owners, cmd.Children[0])
 	return nil
 }
 
+func coFinally(cmpl *compiler) error {
+	cmd := core.CmdBlock{ID: core.StackFinally, CmdCommon: core.CmdCommon{TokenID: uint32(cmpl.pos)}}
+	appendCmd(cmpl, &cmd)
+	cmpl.owners = append(cmpl.owners, &cmd)
+	return nil
+}
+
+// isFinally returns true if the try statement has finally block
+func isFinally(cmd *core.CmdBlock) bool {
+	last := cmd.Children[len(cmd.Children)-1]
+	return last.GetType() == core.CtStack && last.(*core.CmdBlock).ID == core.StackFinally
+}
+
 func isInCatch(cmpl *compiler) bool {
 	for _, item := range cmpl.owners {
 		if item.GetType() == core.CtStack {
 			parent := item.(*core.CmdBlock).Parent
-			if parent != nil && parent.ID == core.StackTry && len(parent.Children) == 2 &&
-				item == parent.Children[1] {
+			if parent != nil && (parent.ID == core.StackCatch || (parent.ID == core.StackTry &&
+				item != parent.Children[0] && item.(*core.CmdBlock).ID != core.StackFinally)) {
 				return true
 			}
 		}
@@ -22,6 +22,7 @@ func InitTypes(ws *core.Workspace) {
 	typeArr := reflect.TypeOf(core.Array{})
 	typeMap := reflect.TypeOf(core.Map{})
 	typeStruct := reflect.TypeOf(core.Struct{})
+	typeChan := reflect.TypeOf(core.Chan{})
 	for _, item := range []initType{
 		{`int`, reflect.TypeOf(int64(0)), ``},
 		{`float`, reflect.TypeOf(float64(0.0)), ``},
@@ -34,6 +35,7 @@ func InitTypes(ws *core.Workspace) {
 		{`keyval`, reflect.TypeOf(core.KeyValue{}), ``},
 		{`struct`, typeStruct, ``},
 		{`fn`, reflect.TypeOf(core.Fn{}), ``},
+		{`iface`, reflect.TypeOf(core.Iface{}), ``},
 		{`thread`, reflect.TypeOf(int64(0)), ``},
 		{`error`, reflect.TypeOf(core.RuntimeError{}), ``},
 		{`obj`, reflect.TypeOf(core.Obj{}), ``},
@@ -47,6 +49,11 @@ func InitTypes(ws *core.Workspace) {
 		{`map.str`, typeMap, `str`},
 		{`map.int`, typeMap, `int`},
 		{`map.bool`, typeMap, `bool`},
+		// chan* is for embedded channel funcs. It means channel of any type
+		{`chan*`, typeChan, ``},
+		{`chan.str`, typeChan, `str`},
+		{`chan.int`, typeChan, `int`},
+		{`chan.bool`, typeChan, `bool`},
 	} {
 		var indexOf core.IObject
 		if len(item.index) > 0 {
@@ -60,6 +67,7 @@ func InitTypes(ws *core.Workspace) {
 	// Define aliases
 	ws.StdLib().NameSpace[`@arr`] = ws.StdLib().NameSpace[`@arr.str`]
 	ws.StdLib().NameSpace[`@map`] = ws.StdLib().NameSpace[`@map.str`]
+	ws.StdLib().NameSpace[`@chan`] = ws.StdLib().NameSpace[`@chan.str`]
 }
 
 // NewStructType adds a new struct type to Unit
@@ -21,6 +21,7 @@ type Bytecode struct {
 	Structs       map[string]uint16
 	StructsList   []StructInfo
 	StructsOffset []int32 // offsets of struct types
+	Methods       map[int64]int32
 	Locals        []Local
 	BlockFlags    int16
 	Pos           []CodePos
@@ -35,6 +36,7 @@ type CodePos struct {
 }
 
 type StructInfo struct {
+	ID     int32 // id of the struct type
 	Name   string
 	Fields []uint16 // types
 	Keys   []string
@@ -46,8 +48,12 @@ type Exec struct {
 	Init    []int32  // offsets of init funcs (initializing constants)
 	Strings []string // string resources
 	Structs []StructInfo
+	Methods map[int64]int32 // ids of the struct methods for interfaces
 	Pos     []CodePos
 	Path    string
+	Cycle   uint64   // limit of loops from the header
+	Depth   uint32   // limit of blocks stack from the header
+	Env     []string // required environment variables from the header
 
 	CRCStdlib uint64
 	CRCCustom uint64
@@ -65,6 +71,7 @@ type Embed struct {
 	Variadic bool        // variadic function
 	Runtime  bool        // the first parameter is rt
 	CanError bool        // can generate error
+	Gas      uint32      // the weight of the function, 0 means the default weight
 }
 
 type AssignIntFunc func(*int64, int64) (int64, error)
@@ -75,21 +82,25 @@ type AssignAnyFunc func(interface{}, interface{}) (interface{}, error)
 //type SetIndexFunc func(interface{}, interface{}, interface{}) error
 
 const (
-	TYPENONE   = 0
-	TYPEINT    = 0x011
-	TYPEBOOL   = 0x021
-	TYPECHAR   = 0x031
-	TYPESTR    = 0x042
-	TYPEFLOAT  = 0x053
-	TYPEARR    = 0x014
-	TYPERANGE  = 0x024
-	TYPEMAP    = 0x034
-	TYPEBUF    = 0x044
-	TYPEFUNC   = 0x054
-	TYPEERROR  = 0x064
-	TYPESET    = 0x074
-	TYPEOBJ    = 0x084
-	TYPESTRUCT = 0x104
+	TYPENONE     = 0
+	TYPEINT      = 0x011
+	TYPEBOOL     = 0x021
+	TYPECHAR     = 0x031
+	TYPESTR      = 0x042
+	TYPEFLOAT    = 0x053
+	TYPEARR      = 0x014
+	TYPERANGE    = 0x024
+	TYPEMAP      = 0x034
+	TYPEBUF      = 0x044
+	TYPEFUNC     = 0x054
+	TYPEERROR    = 0x064
+	TYPESET      = 0x074
+	TYPEOBJ      = 0x084
+	TYPEARRINT   = 0x094 // arr.int
+	TYPEARRFLOAT = 0x0a4 // arr.float
+	TYPEIFACE    = 0x0b4
+	TYPECHAN     = 0x0c4
+	TYPESTRUCT   = 0x104
 
 	BlBreak    = 0x0001
 	BlContinue = 0x0002
@@ -98,6 +109,12 @@ const (
 	BlTry      = 0x0010
 	BlRecover  = 0x0020
 	BlRetry    = 0x0040
+	BlFinally  = 0x0080
+
+	SelReceive = 0x0001 // receives the value from the channel
+	SelSend    = 0x0002 // sends the value to the channel
+	SelTimeout = 0x0003 // waits for the specified milliseconds
+	SelDefault = 0x0004 // is run if the other cases are not ready
 )
 
 const (
@@ -115,6 +132,7 @@ const (
 	PUSHFLOAT // + float64
 	PUSHSTR   // & (strid << 16 )
 	PUSHFUNC  // + id func
+	CLOSURE   // & (count<<16) + id func + {type<<16 | idvar} creates fn with captured variables
 	ADD       // int + int
 	SUB       // int - int
 	MUL       // int * int
@@ -159,7 +177,7 @@ const (
 	OPTPARS   // & (count << 16) + {type<<16 | idvar}
 	INITOBJ   // & (count<<16) create a new object + int16 type +int16 type item
 	RANGE     // create range
-	ARRAY     // &(count<<16) create array + int32 types
+	ARRAY     // &(count<<16) create array + int32 type of array + int32 types
 	LEN       // & (type<<16) length
 	FORINC    // & (index<<16) increment counter
 	BREAK     // break
@@ -170,11 +188,26 @@ const (
 	END       // end of the function
 	CONSTBYID // + int32 id of the object
 	CALLBYID  // & (par count<<16) + int32 id of the object
+	TAILCALL  // & (par count<<16) + int32 count of int, float, str, any pars + int32 id of the object
 	GOBYID    // & (par count<<16) + int32 id of the object new thread + int32 type of pars
 	EMBED     // & (embed id << 16) calls embedded func + int32 count for variadic funcs
 	// + [variadic types]
-	LOCAL // & (par count << 16)+ int32 offset
-	IOTA  // & (iota<<16)
+	LOCAL      // & (par count << 16)+ int32 offset
+	IOTA       // & (iota<<16)
+	INCVAR     // & (block shift<<16) + int16 post flag + int16 index + int32 value
+	METHOD     // & (any shift<<16) + int32 method of the interface pushes fn of the struct method
+	RETS       // + int32 count of int, float, str, any results returns several values from function
+	FINALLY    // starts finally code of the block
+	ENDFINALLY // continues return, break, continue or error after finally code
+	DEFER      // appends fn to the deferred calls
+	DEFERS     // runs the deferred calls
+	CATCH      // & (type<<16) + int32 jump if the error doesn't match the filter
+	THROW      // throws the error again
+	SEND       // & (type<<16) sends the value to the channel
+	RECEIVE    // & (type<<16) + int32 jump if the channel has been closed
+	RECEIVEOK  // & (type<<16) receives the value and false if the channel has been closed
+	SELECT     // & (count<<16) + int32 end + count*(int32 (type<<16)|kind + int32 jump)
+	BOX        // & (index<<16) + int32 type creates the box of the variable
 
 	INDEX        // & (int32 count) + {(type input<<16) + result type}
 	ASSIGNPTR    // & (int16 type << 16)
@@ -193,3 +226,8 @@ const (
 
 	EMBEDFUNC
 )
+
+// MethodKey returns the key in Methods for the struct type and the method of the interface
+func MethodKey(structID, method int32) int64 {
+	return int64(structID)<<32 | int64(method)
+}
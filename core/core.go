@@ -86,6 +86,28 @@ const (
 	StackLocret
 	// StackTry is the try statement
 	StackTry
+	// StackIface converts the struct to the interface
+	StackIface
+	// StackMethod gets the method of the interface
+	StackMethod
+	// StackDestruct assigns the results of the function to several variables
+	StackDestruct
+	// StackFinally is the finally block of the try statement
+	StackFinally
+	// StackDefer is the defer statement
+	StackDefer
+	// StackCatch is the catch block with the filter of errors
+	StackCatch
+	// StackSend sends the value to the channel
+	StackSend
+	// StackReceive receives the value from the channel
+	StackReceive
+	// StackReceiveOk receives the value from the channel and the flag of success
+	StackReceiveOk
+	// StackSelect is the select statement
+	StackSelect
+	// StackSelectCase is the case of the select statement
+	StackSelectCase
 )
 
 // Token is a lexical token.
@@ -167,6 +189,7 @@ type CmdBlock struct {
 	Locals     []ICmd
 	LocalNames map[string]int
 	Children   []ICmd
+	Boxed      map[int]bool // variables shared with function literals, true if the block creates the box
 }
 
 // CmdUnary calls an unary function
@@ -189,12 +212,13 @@ type CmdBinary struct {
 // CmdAnyFunc calls a function with more than 2 parameters
 type CmdAnyFunc struct {
 	CmdCommon
-	Object   IObject
-	Result   *TypeObject
-	Children []ICmd
-	FnVar    ICmd
-	Optional []int // indexes of optional variables
-	IsThread bool
+	Object    IObject
+	Result    *TypeObject
+	Children  []ICmd
+	FnVar     ICmd
+	Optional  []int // indexes of optional variables or captured variables of the closure
+	IsThread  bool
+	IsClosure bool // creates fn value of the function literal with captured variables
 }
 
 // GetType returns CtValue
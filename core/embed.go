@@ -30,6 +30,8 @@ const (
 	DefAssignStructStruct = `AssignºStructStruct`
 	// DefAssignBitAndStructStruct equals struct &= struct
 	DefAssignBitAndStructStruct = `AssignBitAndºStructStruct`
+	// DefAssignIfaceIface equals iface = iface
+	DefAssignIfaceIface = `AssignºIfaceIface`
 	// DefAssignFnFn equals fn = fn
 	DefAssignFnFn = `AssignºFnFn`
 	// DefAssignBitAndArrArr equals arr &= arr
@@ -40,6 +42,12 @@ const (
 	DefNewKeyValue = `NewKeyValue`
 	// DefGetEnv returns an environment variable
 	DefGetEnv = `GetEnv`
+	// DefMapArr calls fn for the items of the array
+	DefMapArr = `MapºArr`
+	// DefParallelMapArr calls fn for the items of the array in parallel
+	DefParallelMapArr = `ParallelMapºArr`
+	// DefAssignChan equals chan = chan
+	DefAssignChan = `AssignºChanChan`
 )
 
 var (
@@ -54,11 +62,15 @@ var (
 		DefAssignIntInt:             true,
 		DefAssignStructStruct:       true,
 		DefAssignFnFn:               true,
+		DefAssignIfaceIface:         true,
 		DefAssignBitAndStructStruct: true,
 		DefAssignBitAndArrArr:       true,
 		DefAssignBitAndMapMap:       true,
 		DefNewKeyValue:              true,
 		DefGetEnv:                   true,
+		DefMapArr:                   true,
+		DefParallelMapArr:           true,
+		DefAssignChan:               true,
 	}
 )
 
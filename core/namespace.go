@@ -14,6 +14,7 @@ const (
 	npConst    = `$`
 	npVariadic = `?`
 	npFunc     = `#`
+	npGeneric  = `!`
 
 	// NSImported means imported object in NameSpace
 	NSImported = 0x10000000
@@ -60,6 +61,10 @@ func (unit *Unit) FindFunc(name string, params []*TypeObject) (IObject, bool) {
 			keyAny += npFunc + `arr*`
 		} else if strings.HasPrefix(parName, `map.`) {
 			keyAny += npFunc + `map*`
+		} else if strings.HasPrefix(parName, `chan.`) {
+			keyAny += npFunc + `chan*`
+		} else if v.Func != nil {
+			keyAny += npFunc + `fn`
 		} else {
 			keyAny += npFunc + parName
 		}
@@ -75,6 +80,11 @@ func (unit *Unit) FindFunc(name string, params []*TypeObject) (IObject, bool) {
 	return unit.FindObj(npVariadic + name), true
 }
 
+// FindGeneric returns the generic function with the specified name
+func (unit *Unit) FindGeneric(name string) IObject {
+	return unit.FindObj(npGeneric + name)
+}
+
 // AddConst appends a constant to NameSpace
 func (unit *Unit) AddConst(name string) {
 	ind := uint32(len(unit.VM.Objects) - 1)
@@ -102,3 +112,11 @@ func (unit *Unit) AddFunc(ind int, obj IObject, pub bool) {
 	}
 	unit.NameSpace[key] = uint32(ind)
 }
+
+// AddGeneric appends the generic function to NameSpace
+func (unit *Unit) AddGeneric(ind int, obj IObject, pub bool) {
+	if pub {
+		ind |= NSPub
+	}
+	unit.NameSpace[npGeneric+obj.GetName()] = uint32(ind)
+}
@@ -46,10 +46,12 @@ type Object struct {
 // TypeObject contains information about the type
 type TypeObject struct {
 	Object
-	Original reflect.Type // Original golang type
-	IndexOf  *TypeObject  // consists of elements
-	Custom   *StructType  // for custom struct type
-	Func     *FnType      // for func type
+	Original reflect.Type  // Original golang type
+	IndexOf  *TypeObject   // consists of elements
+	Custom   *StructType   // for custom struct type
+	Iface    *IfaceType    // for interface type
+	Func     *FnType       // for func type
+	Tuple    []*TypeObject // for several results of the function
 }
 
 // EmbedObject contains information about the golang function
@@ -63,10 +65,19 @@ type EmbedObject struct {
 	CanError bool          // can generate error
 }
 
+// Generic contains information about the generic function
+type Generic struct {
+	Params  []string // the type names of parameters
+	Results []string // the type names of results
+	Start   int      // the index of the first token of the function
+	End     int      // the index of the last token of the function
+}
+
 // FuncObject contains information about the function
 type FuncObject struct {
 	Object
-	Block CmdBlock
+	Block   CmdBlock
+	Generic *Generic // for the generic function
 }
 
 // ConstObject contains information about the constant
@@ -263,6 +274,7 @@ func (unit *Unit) NewType(name string, original reflect.Type, indexOf IObject) I
 		},
 		Original: original,
 	}
+	typeObject.ObjID = int32(len(unit.VM.Objects))
 	if indexOf != nil {
 		typeObject.IndexOf = indexOf.(*TypeObject)
 	}
@@ -7,6 +7,7 @@ package core
 import (
 	"fmt"
 	"strings"
+	"sync"
 )
 
 const (
@@ -37,6 +38,16 @@ type Array struct {
 	Data []interface{}
 }
 
+// ArrayInt is an array of integers
+type ArrayInt struct {
+	Data []int64
+}
+
+// ArrayFloat is an array of float numbers
+type ArrayFloat struct {
+	Data []float64
+}
+
 // Buffer is []byte
 type Buffer struct {
 	Data []byte
@@ -70,6 +81,18 @@ type StructType struct {
 	Types  []*TypeObject    // Types of fields
 }
 
+// IfaceType is used for interface types
+type IfaceType struct {
+	Fields  map[string]*TypeObject        // Types of required fields
+	Names   []string                      // Names of required methods
+	Methods []*FnType                     // Types of required methods without the struct parameter
+	Impl    map[*TypeObject][]*FuncObject // Methods of struct types which implement the interface
+}
+
+// Iface is used for interface types. The value of the interface is a struct.
+type Iface struct {
+}
+
 // Struct is used for custom struct types
 type Struct struct {
 	Type   *TypeObject
@@ -81,6 +104,14 @@ type Obj struct {
 	Data interface{}
 }
 
+// Chan is a channel for the communication between threads
+type Chan struct {
+	Data   chan interface{}
+	Done   chan struct{} // it is closed when the channel has been closed
+	closed bool
+	mutex  sync.Mutex
+}
+
 // Len is part of Indexer interface.
 func (prange *Range) Len() int {
 	if prange.From < prange.To {
@@ -201,6 +232,98 @@ func (arr *Array) SetIndex(index, value interface{}) int {
 	return 0
 }
 
+// String interface for ArrayInt
+func (arr ArrayInt) String() string {
+	return fmt.Sprint(arr.Data)
+}
+
+// NewArrayInt creates a new array of integers
+func NewArrayInt() *ArrayInt {
+	return &ArrayInt{
+		Data: make([]int64, 0),
+	}
+}
+
+// Len is part of sort.Interface.
+func (arr *ArrayInt) Len() int {
+	return len(arr.Data)
+}
+
+// Swap is part of sort.Interface.
+func (arr *ArrayInt) Swap(i, j int) {
+	arr.Data[i], arr.Data[j] = arr.Data[j], arr.Data[i]
+}
+
+// Less is part of sort.Interface.
+func (arr *ArrayInt) Less(i, j int) bool {
+	return arr.Data[i] < arr.Data[j]
+}
+
+// GetIndex is part of Indexer interface.
+func (arr *ArrayInt) GetIndex(index interface{}) (interface{}, bool) {
+	aindex := int(index.(int64))
+	if aindex < 0 || aindex >= len(arr.Data) {
+		return nil, false
+	}
+	return arr.Data[aindex], true
+}
+
+// SetIndex is part of Indexer interface.
+func (arr *ArrayInt) SetIndex(index, value interface{}) int {
+	aindex := int(index.(int64))
+	if aindex < 0 || aindex >= len(arr.Data) {
+		return ErrIndexOut
+	}
+	arr.Data[aindex] = value.(int64)
+	return 0
+}
+
+// String interface for ArrayFloat
+func (arr ArrayFloat) String() string {
+	return fmt.Sprint(arr.Data)
+}
+
+// NewArrayFloat creates a new array of float numbers
+func NewArrayFloat() *ArrayFloat {
+	return &ArrayFloat{
+		Data: make([]float64, 0),
+	}
+}
+
+// Len is part of sort.Interface.
+func (arr *ArrayFloat) Len() int {
+	return len(arr.Data)
+}
+
+// Swap is part of sort.Interface.
+func (arr *ArrayFloat) Swap(i, j int) {
+	arr.Data[i], arr.Data[j] = arr.Data[j], arr.Data[i]
+}
+
+// Less is part of sort.Interface.
+func (arr *ArrayFloat) Less(i, j int) bool {
+	return arr.Data[i] < arr.Data[j]
+}
+
+// GetIndex is part of Indexer interface.
+func (arr *ArrayFloat) GetIndex(index interface{}) (interface{}, bool) {
+	aindex := int(index.(int64))
+	if aindex < 0 || aindex >= len(arr.Data) {
+		return nil, false
+	}
+	return arr.Data[aindex], true
+}
+
+// SetIndex is part of Indexer interface.
+func (arr *ArrayFloat) SetIndex(index, value interface{}) int {
+	aindex := int(index.(int64))
+	if aindex < 0 || aindex >= len(arr.Data) {
+		return ErrIndexOut
+	}
+	arr.Data[aindex] = value.(float64)
+	return 0
+}
+
 // String interface for Buffer
 func (buf Buffer) String() string {
 	return fmt.Sprint(buf.Data)
@@ -412,3 +535,35 @@ func (obj *Obj) SetIndex(index, value interface{}) int {
 	}
 	return ErrObjValue
 }
+
+// NewChan creates a new channel with the specified size of the buffer
+func NewChan(size int) *Chan {
+	return &Chan{
+		Data: make(chan interface{}, size),
+		Done: make(chan struct{}),
+	}
+}
+
+// Close closes the channel. It returns false if the channel has already been closed.
+func (ch *Chan) Close() bool {
+	ch.mutex.Lock()
+	defer ch.mutex.Unlock()
+	if ch.closed {
+		return false
+	}
+	ch.closed = true
+	close(ch.Done)
+	return true
+}
+
+// IsClosed returns true if the channel has been closed
+func (ch *Chan) IsClosed() bool {
+	ch.mutex.Lock()
+	defer ch.mutex.Unlock()
+	return ch.closed
+}
+
+// String interface for Chan
+func (ch *Chan) String() string {
+	return fmt.Sprintf(`chan[%d/%d]`, len(ch.Data), cap(ch.Data))
+}
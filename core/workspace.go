@@ -20,6 +20,8 @@ type Workspace struct {
 	Linked    map[string]int // compiled files
 	IotaID    int32
 	Embedded  []Embed
+	// NoOptimize disables the optimizations of the bytecode
+	NoOptimize bool
 }
 
 const (
@@ -117,9 +119,10 @@ func (ws *Workspace) Unit(name string) *Unit {
 	return ws.Units[ws.UnitNames[name]]
 }
 
+// GetHeader returns the value of name = value line in the # header
 func (unit *Unit) GetHeader(name string) string {
 	for _, line := range strings.Split(unit.Lexeme.Header, "\n") {
-		ret := regexp.MustCompile(`\s*` + strings.ReplaceAll(name, `.`, `\.`) +
+		ret := regexp.MustCompile(`^` + strings.ReplaceAll(name, `.`, `\.`) +
 			`\s*=\s*(.*)$`).FindStringSubmatch(strings.TrimSpace(line))
 		if len(ret) == 2 {
 			return ret[1]
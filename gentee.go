@@ -39,6 +39,7 @@ type Gentee struct {
 type EmbedItem struct {
 	Prototype string
 	Object    interface{}
+	Gas       uint32 // the weight of the function for gas metering
 }
 
 // Custom is a structure with parameters for compiling and runtime
@@ -102,12 +103,12 @@ func Customize(custom *Custom) error {
 	for _, v := range custom.Embedded {
 		v.Prototype = strings.ReplaceAll(v.Prototype, ` `, ``)
 		if len(v.Prototype) == 0 || v.Object == nil {
-			return fmt.Errorf("%s %v", vm.ErrorText(vm.ErrCustom), v)
+			return fmt.Errorf("%s {%s %v}", vm.ErrorText(vm.ErrCustom), v.Prototype, v.Object)
 		}
 		list := re.FindAllStringSubmatch(v.Prototype, -1)
 		vals := list[0]
 		if len(vals) < 4 {
-			return fmt.Errorf("%s %v", vm.ErrorText(vm.ErrCustom), v)
+			return fmt.Errorf("%s {%s %v}", vm.ErrorText(vm.ErrCustom), v.Prototype, v.Object)
 		}
 		t := reflect.TypeOf(v.Object)
 		embed := core.Embed{
@@ -121,6 +122,7 @@ func Customize(custom *Custom) error {
 			Variadic: t.IsVariadic(),
 			Runtime:  t.NumIn() > 0 && t.In(0) == reflect.TypeOf(&vm.Runtime{}),
 			CanError: t.NumOut() >= 1 && t.Out(t.NumOut()-1).String() == `error`,
+			Gas:      v.Gas,
 		}
 		vm.EmbedFuncs = append(vm.EmbedFuncs, embed)
 	}
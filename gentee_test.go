@@ -7,10 +7,15 @@ package gentee
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"syscall"
 	"testing"
+	"time"
+
+	"github.com/gentee/gentee/vm"
 )
 
 // Source contains source code and result value
@@ -153,3 +158,305 @@ func TestGentee(t *testing.T) {
 		return
 	}
 }
+
+// compileTest compiles the source code of the test
+func compileTest(t *testing.T, src string) *Exec {
+	exec, _, err := New().Compile(src, ``)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return exec
+}
+
+// checkRun runs the bytecode and compares the result with want. If there is an error then
+// its text must end with want.
+func checkRun(exec *Exec, settings Settings, want string) error {
+	result, err := exec.Run(settings)
+	if err != nil {
+		if strings.HasSuffix(err.Error(), want) {
+			return nil
+		}
+		return fmt.Errorf("error != want;\n%v !=\n%s", err, want)
+	}
+	return getWant(result, want)
+}
+
+func TestStack(t *testing.T) {
+	exec := compileTest(t, `func sum(int n) int {
+  if n == 0 : return 0
+  return n + sum(n-1)
+}
+run int {
+  return sum(400)
+}`)
+	if err := checkRun(exec, Settings{}, `80200`); err != nil {
+		t.Error(err)
+	}
+	var settings Settings
+	settings.Stack = 300
+	if err := checkRun(exec, settings, `stack overflow`); err != nil {
+		t.Error(err)
+	}
+	exec = compileTest(t, `func endless(int n) int {
+  return endless(n+1) + 1
+}
+run int {
+  return endless(0)
+}`)
+	settings = Settings{}
+	settings.Depth = 500
+	if err := checkRun(exec, settings, `maximum depth of recursion has been reached`); err != nil {
+		t.Error(err)
+	}
+	settings.Depth = 0
+	settings.MaxMemory = 4 << 20
+	if err := checkRun(exec, settings, `memory limit has been exceeded`); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMemory(t *testing.T) {
+	exec := compileTest(t, `run str {
+  str s = "0123456789"
+  str ret
+  try {
+    for i in 1..30 : s += s
+  } catch err {
+    ret = ErrText(err) + str(*s > 1000000)
+    recover
+  }
+  return ret
+}`)
+	var settings Settings
+	settings.MaxMemory = 10 << 20
+	settings.Stats = &vm.Stats{}
+	if err := checkRun(exec, settings, `memory limit has been exceeded`+`true`); err != nil {
+		t.Error(err)
+		return
+	}
+	if settings.Stats.PeakMemory <= 10<<20 || settings.Stats.PeakMemory > 30<<20 {
+		t.Errorf(`wrong peak memory %d`, settings.Stats.PeakMemory)
+	}
+	exec = compileTest(t, `run int {
+  arr.str list
+  for i in 1..1000 : list += Repeat(str(i%10), 100)
+  return *list
+}`)
+	settings.MaxMemory = 0
+	if err := checkRun(exec, settings, `1000`); err != nil {
+		t.Error(err)
+		return
+	}
+	if settings.Stats.PeakMemory < 100000 || settings.Stats.PeakMemory > 1<<20 {
+		t.Errorf(`wrong peak memory %d`, settings.Stats.PeakMemory)
+	}
+	settings.MaxMemory = 50000
+	if err := checkRun(exec, settings, `memory limit has been exceeded`); err != nil {
+		t.Error(err)
+		return
+	}
+	exec = compileTest(t, `run int {
+  arr.int ai
+  arr.float af
+  arr.bool ab
+  for i in 1..200000 {
+    ai += i
+    af += 1.5
+    ab += true
+  }
+  return *ai + *af + *ab
+}`)
+	settings.MaxMemory = 0
+	if err := checkRun(exec, settings, `600000`); err != nil {
+		t.Error(err)
+		return
+	}
+	if settings.Stats.PeakMemory < 4800000 || settings.Stats.PeakMemory > 20<<20 {
+		t.Errorf(`wrong peak memory %d`, settings.Stats.PeakMemory)
+		return
+	}
+	settings.MaxMemory = 1 << 20
+	if err := checkRun(exec, settings, `memory limit has been exceeded`); err != nil {
+		t.Error(err)
+		return
+	}
+	exec = compileTest(t, `run bool {
+  set s
+  s[3000000] = true
+  return s[3000000]
+}`)
+	settings.MaxMemory = 0
+	if err := checkRun(exec, settings, `true`); err != nil {
+		t.Error(err)
+		return
+	}
+	if settings.Stats.PeakMemory < 3000000/8 {
+		t.Errorf(`wrong peak memory %d`, settings.Stats.PeakMemory)
+		return
+	}
+	settings.MaxMemory = 100000
+	if err := checkRun(exec, settings, `memory limit has been exceeded`); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGas(t *testing.T) {
+	exec := compileTest(t, `run int {
+  int sum
+  try {
+    for i in 1..1000 : sum += Find("abcdef", "e")
+  } catch err {
+    recover
+  }
+  return sum
+}`)
+	var settings Settings
+	settings.Stats = &vm.Stats{}
+	if err := checkRun(exec, settings, `4000`); err != nil {
+		t.Error(err)
+		return
+	}
+	gas := settings.Stats.Gas
+	if gas < 1000*vm.GASEMBED {
+		t.Errorf(`wrong gas %d`, gas)
+		return
+	}
+	settings.Gas = gas
+	if err := checkRun(exec, settings, `4000`); err != nil || settings.Stats.Gas != gas {
+		t.Errorf(`wrong gas %d %v`, settings.Stats.Gas, err)
+		return
+	}
+	settings.Gas = gas - 1
+	if err := checkRun(exec, settings, `gas limit has been exceeded`); err != nil {
+		t.Error(err)
+		return
+	}
+	exec = compileTest(t, `run int {
+  int sum
+  thread th = go {
+    int count
+    for i in 1..1000 : count += Find("abcdef", "e")
+  }
+  wait(th)
+  for i in 1..1000 : sum += Find("abcdef", "e")
+  return sum
+}`)
+	settings.Gas = 0
+	if err := checkRun(exec, settings, `4000`); err != nil {
+		t.Error(err)
+		return
+	}
+	if settings.Stats.Gas < 2*gas {
+		t.Errorf(`wrong gas of threads %d`, settings.Stats.Gas)
+		return
+	}
+	settings.Gas = gas + gas/2
+	if err := checkRun(exec, settings, `gas limit has been exceeded`); err != nil {
+		t.Error(err)
+		return
+	}
+	// threads reserve only the rest of the limit
+	if settings.Stats.Gas-settings.Gas >= vm.GASBATCH {
+		t.Errorf(`wrong gas of threads %d`, settings.Stats.Gas)
+	}
+}
+
+func TestNonFatalThreads(t *testing.T) {
+	exec := compileTest(t, `run str {
+  thread th = go {
+    error(101, "thread error")
+  }
+  thread ok = go {
+    sleep(50)
+    return 7
+  }
+  str ret
+  try {
+    WaitResult(th)
+  } catch err {
+    ret = ErrText(err)
+    recover
+  }
+  return ret + str(ThreadStatus(th) == TH_ERROR) + str(int(WaitResult(ok)))
+}`)
+	var settings Settings
+	if err := checkRun(exec, settings, `thread error`); err != nil {
+		t.Error(err)
+		return
+	}
+	settings.NonFatalThreads = true
+	if err := checkRun(exec, settings, `thread error`+`true7`); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSignal(t *testing.T) {
+	tmpFile := filepath.Join(os.TempDir(), `gentee_signal.txt`)
+	defer os.Remove(tmpFile)
+	exec := compileTest(t, fmt.Sprintf(`fn handler() bool
+run str {
+  OnSignal("SIGHUP", fn() bool {
+    return AtomicAdd("hup", 1) == 2
+  })
+  thread th = go {
+    try {
+      sleep(10000)
+    } finally {
+      WriteFile(%q, "closed")
+    }
+  }
+  wait(th)
+  return "not stopped"
+}`, tmpFile))
+	go func() {
+		process, _ := os.FindProcess(os.Getpid())
+		for i := 0; i < 2; i++ {
+			time.Sleep(200 * time.Millisecond)
+			process.Signal(syscall.SIGHUP)
+		}
+	}()
+	start := time.Now()
+	if err := checkRun(exec, Settings{}, `script has been stopped by signal SIGHUP`); err != nil {
+		t.Error(err)
+		return
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf(`sleep has not been interrupted`)
+	}
+	if data, _ := ioutil.ReadFile(tmpFile); string(data) != `closed` {
+		t.Errorf(`finally has not been executed`)
+	}
+}
+
+func TestExit(t *testing.T) {
+	exec := compileTest(t, `run str {
+  thread th = go {
+    sleep(50)
+    Exit(7)
+  }
+  try {
+    wait(th)
+  } catch err {
+    recover
+  }
+  return "not exited"
+}`)
+	result, err := exec.Run(Settings{})
+	if exit, ok := err.(*vm.ExitError); !ok || exit.Code != 7 || result != nil {
+		t.Errorf(`wrong exit %v %v`, result, err)
+	}
+}
+
+func TestHeaderEnv(t *testing.T) {
+	exec := compileTest(t, "# env = GENTEE_ENV_A, GENTEE_ENV_B\nrun str {\n  return $GENTEE_ENV_B\n}")
+	os.Setenv(`GENTEE_ENV_A`, `a`)
+	os.Unsetenv(`GENTEE_ENV_B`)
+	if err := checkRun(exec, Settings{}, `environment variable GENTEE_ENV_B is not defined`); err != nil {
+		t.Error(err)
+		return
+	}
+	os.Setenv(`GENTEE_ENV_B`, `ok`)
+	if err := checkRun(exec, Settings{}, `ok`); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,18 @@
+run int {
+    int ret
+    for i in 1..50000 {
+        int k = i % 5
+        if k == 0 {
+            ret += 3
+        } elif k == 1 {
+            ret += 2
+        } elif k == 2 {
+            if i > 100 {
+                ret++
+            }
+        } else {
+            ret--
+        }
+    }
+    return ret
+}
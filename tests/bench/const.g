@@ -0,0 +1,10 @@
+run int {
+    int sum
+    for i in 1..100000 {
+        sum += (60 * 60 * 24) % 1000 + (1 << 4) - 2*(3+4)
+        if 2 > 10 || 5 == 4 {
+            sum--
+        }
+    }
+    return sum
+}
@@ -0,0 +1,13 @@
+func fib(int n) int {
+    if n < 2 : return n
+    return fib(n-1) + fib(n-2)
+}
+
+func sum(int n acc) int {
+    if n == 0 : return acc
+    return sum(n-1, acc+n)
+}
+
+run int {
+    return fib(20) + sum(10000, 0)
+}
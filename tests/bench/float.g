@@ -0,0 +1,12 @@
+run int {
+    arr.float list
+    for i in 0..9999 : list += float(i) / 2.0
+    float sum
+    for k in 1..10 {
+        for i in 1..9999 {
+            list[i] = list[i-1] * 0.5 + list[i]
+            sum += list[i]
+        }
+    }
+    return int(sum)
+}
@@ -0,0 +1,11 @@
+run int {
+    int sum i
+    while i < 100000 {
+        sum += i % 7
+        i++
+    }
+    for j in 1..100000 {
+        sum = sum - 1
+    }
+    return sum
+}
@@ -0,0 +1,19 @@
+run int {
+    int high = 100000
+    int count
+    arr.int sieve
+    for i in 0..high : sieve += 0
+    for i in 2..high/2 {
+        if sieve[i] == 0 {
+            int j = i + i
+            while j <= high {
+                sieve[j] = 1
+                j += i
+            }
+        }
+    }
+    for i in 2..high {
+        if sieve[i] == 0 : count++
+    }
+    return count
+}
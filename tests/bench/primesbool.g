@@ -0,0 +1,19 @@
+run int {
+    int high = 100000
+    int count
+    arr.bool sieve
+    for i in 0..high : sieve += false
+    for i in 2..high/2 {
+        if !sieve[i] {
+            int j = i + i
+            while j <= high {
+                sieve[j] = true
+                j += i
+            }
+        }
+    }
+    for i in 2..high {
+        if !sieve[i] : count++
+    }
+    return count
+}
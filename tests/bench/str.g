@@ -0,0 +1,7 @@
+run int {
+    str s
+    for i in 1..5000 {
+        s += "a" + "b" + str(i % 10)
+    }
+    return *s
+}
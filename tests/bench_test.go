@@ -0,0 +1,73 @@
+// Copyright 2019 Alexey Krivonogov. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	gentee "github.com/gentee/gentee"
+)
+
+// benchScript runs the script with and without the optimizations of the bytecode
+func benchScript(b *testing.B, name string, want string) {
+	for _, noOptimize := range []bool{false, true} {
+		title := `optimized`
+		if noOptimize {
+			title = `plain`
+		}
+		b.Run(title, func(b *testing.B) {
+			workspace := gentee.New()
+			workspace.NoOptimize = noOptimize
+			exec, _, err := workspace.CompileFile(filepath.Join(`bench`, name+`.g`))
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				result, err := exec.Run(gentee.Settings{})
+				if err != nil {
+					b.Fatal(err)
+				}
+				if fmt.Sprint(result) != want {
+					b.Fatalf(`%s: %v != %s`, name, result, want)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkLoop(b *testing.B) {
+	benchScript(b, `loop`, `199995`)
+}
+
+func BenchmarkConst(b *testing.B) {
+	benchScript(b, `const`, `40200000`)
+}
+
+func BenchmarkRecursion(b *testing.B) {
+	benchScript(b, `fib`, `50011765`)
+}
+
+func BenchmarkBranch(b *testing.B) {
+	benchScript(b, `branch`, `39980`)
+}
+
+func BenchmarkStr(b *testing.B) {
+	benchScript(b, `str`, `15000`)
+}
+
+func BenchmarkPrimes(b *testing.B) {
+	benchScript(b, `primes`, `9592`)
+}
+
+func BenchmarkPrimesBool(b *testing.B) {
+	benchScript(b, `primesbool`, `9592`)
+}
+
+func BenchmarkFloat(b *testing.B) {
+	benchScript(b, `float`, `51052771319`)
+}
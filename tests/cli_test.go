@@ -84,7 +84,37 @@ func TestCli(t *testing.T) {
 			[]string{`cmdline.g`, `-i:10`, `-`, `-two`, `'three four'`, `five`}},
 		{`один+*.два+.три+Welcome+"-option"+"-"truetrue`,
 			[]string{`cmdline.g`, `один`, `*.два`, `.три`, `Welcome`, `"-option"`, `"-"`}},
+		{`10 false 1.5 [a b] x []`, []string{`options.g`, `-n=x`}},
+		{`-3 true 2 [q w] x [one -two]`, []string{`options.g`, `-n`, `x`, `-c`, `-3`,
+			`--verbose`, `-t`, `q`, `w`, `--ratio:2`, `one`, `--`, `-two`}},
+		{`10 false 1.5 [q -] x [- one]`, []string{`options.g`, `-t`, `q`, `-`, `-n`, `x`,
+			`-`, `one`}},
+		{"ERROR #254: .../tests/scripts/options.g [7:16] option name is required\n" +
+			".../tests/scripts/options.g [7:16] run -> ParseArgs", []string{`options.g`}},
+		{"ERROR #254: .../tests/scripts/options.g [7:16] invalid value of option count\n" +
+			".../tests/scripts/options.g [7:16] run -> ParseArgs", []string{`options.g`, `-n=x`, `-c`, `abc`}},
+		{"ERROR #254: .../tests/scripts/options.g [7:16] unknown option --size\n" +
+			".../tests/scripts/options.g [7:16] run -> ParseArgs", []string{`options.g`, `--size`, `1`}},
+		{"Usage: options [options] [arguments]\n\nOptions:\n" +
+			"  -c, --count int    number of items (default: 10)\n" +
+			"  -v, --verbose      print details\n" +
+			"      --ratio float  ratio of items (default: 1.5)\n" +
+			"  -t, --tags arr     list of tags (default: a,b)\n" +
+			"  -n, --name str     name of the item (required)\n" +
+			"  -h, --help         print this help", []string{`options.g`, `--help`}},
 		{"ok 777\n", []string{`ok.g`}},
+		{`gen\nlint\nbuild`, []string{`tasks.g`}},
+		{`gen\nlint\nbuild\ntest`, []string{`tasks.g`, `test`, `lint`}},
+		{`gen\nlint`, []string{`tasks.g`, `-n`, `x`, `lint`}},
+		{"Tasks:\n  build  build the project (depends on: gen, lint)\n  gen    generate sources\n" +
+			"  lint   check sources (depends on: gen)\n  test   run tests (depends on: build)\n" +
+			"  loop   cyclic task (depends on: loop)", []string{`tasks.g`, `-list`}},
+		{"ERROR #254: .../tests/scripts/tasks.g [8:5] cyclic dependency of task loop\n" +
+			".../tests/scripts/tasks.g [8:5] run -> RunTasks", []string{`tasks.g`, `loop`}},
+		{"ERROR #254: .../tests/scripts/tasks.g [8:5] unknown option -z\n" +
+			".../tests/scripts/tasks.g [8:5] run -> RunTasks", []string{`tasks.g`, `-z`, `lint`}},
+		{"Prints the limits of the script\nUsage: info.g", []string{`-info`, `info.g`}},
+		{`100 50`, []string{`info.g`}},
 		{"test", []string{`runname.g`}},
 		{core.Version, []string{`-ver`}},
 		{``, []string{`nothing.g`}},
@@ -132,4 +162,9 @@ func TestCli(t *testing.T) {
 			return
 		}
 	}
+	cmd = exec.Command(outputFile, `scripts/exit.g`)
+	if stdout, err = cmd.CombinedOutput(); string(stdout) != `finally` ||
+		cmd.ProcessState.ExitCode() != 5 {
+		t.Errorf(`wrong exit %s %v`, stdout, err)
+	}
 }
@@ -0,0 +1,8 @@
+run {
+    try {
+        Exit(5)
+    } finally {
+        Print(`finally`)
+    }
+    Print(`not exited`)
+}
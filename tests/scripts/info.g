@@ -0,0 +1,10 @@
+# description = Prints the limits of the script
+# usage = info.g
+# version = 1.8
+# cycle = 100
+# depth = 50
+# env = PATH
+
+run str {
+    return Format(`%d %d`, CYCLE, DEPTH)
+}
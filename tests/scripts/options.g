@@ -0,0 +1,11 @@
+run options str {
+    ArgOption(`count`, `c`, `int`, `10`, `number of items`)
+    ArgOption(`verbose`, `v`, `bool`, ``, `print details`)
+    ArgOption(`ratio`, ``, `float`, `1.5`, `ratio of items`)
+    ArgOption(`tags`, `t`, `arr`, `a,b`, `list of tags`)
+    ArgRequired(`name`, `n`, `str`, `name of the item`)
+    obj opts = ParseArgs()
+    return str(int(opts[`count`])) + ` ` + str(bool(opts[`verbose`])) + ` ` +
+        str(float(opts[`ratio`])) + ` ` + str(opts[`tags`]) + ` ` + str(opts[`name`]) + ` ` +
+        str(opts[`_`])
+}
@@ -0,0 +1,9 @@
+run {
+    Task(`build`, fn() { Println(`build`) }, `build the project`, `gen`, `lint`)
+    Task(`gen`, fn() { Println(`gen`) }, `generate sources`)
+    Task(`lint`, fn() { Println(`lint`) }, `check sources`, `gen`)
+    Task(`test`, fn() { Println(`test`) }, `run tests`, `build`)
+    Task(`loop`, fn() { }, `cyclic task`, `loop`)
+    ArgOption(`name`, `n`, `str`, ``, `name of the build`)
+    RunTasks(false)
+}
@@ -14,21 +14,35 @@ import (
 
 // AssignAddºArr appends one array to another one
 func AssignAddºArr(dest interface{}, src interface{}) (interface{}, error) {
-	for _, item := range src.(*core.Array).Data {
-		dest.(*core.Array).Data = append(dest.(*core.Array).Data, item)
+	switch v := dest.(type) {
+	case *core.ArrayInt:
+		v.Data = append(v.Data, src.(*core.ArrayInt).Data...)
+	case *core.ArrayFloat:
+		v.Data = append(v.Data, src.(*core.ArrayFloat).Data...)
+	default:
+		for _, item := range src.(*core.Array).Data {
+			dest.(*core.Array).Data = append(dest.(*core.Array).Data, item)
+		}
 	}
 	return dest, nil
 }
 
 // AssignAddºArrAny appends an item to array
 func AssignAddºArrAny(arr interface{}, value interface{}) (interface{}, error) {
-	arr.(*core.Array).Data = append(arr.(*core.Array).Data, value)
+	switch v := arr.(type) {
+	case *core.ArrayInt:
+		v.Data = append(v.Data, value.(int64))
+	case *core.ArrayFloat:
+		v.Data = append(v.Data, value.(float64))
+	default:
+		arr.(*core.Array).Data = append(arr.(*core.Array).Data, value)
+	}
 	return arr, nil
 }
 
 // boolºArr converts array to bool
-func boolºArr(val *core.Array) int64 {
-	if len(val.Data) == 0 {
+func boolºArr(val core.Indexer) int64 {
+	if val.Len() == 0 {
 		return 0
 	}
 	return 1
@@ -44,32 +58,290 @@ func JoinºArrStr(value *core.Array, sep string) string {
 }
 
 // ReverseºArr reverses an array
-func ReverseºArr(arr *core.Array) *core.Array {
-	for i, j := 0, len(arr.Data)-1; i < j; i, j = i+1, j-1 {
-		arr.Data[i], arr.Data[j] = arr.Data[j], arr.Data[i]
+func ReverseºArr(arr core.Indexer) core.Indexer {
+	switch v := arr.(type) {
+	case *core.ArrayInt:
+		for i, j := 0, len(v.Data)-1; i < j; i, j = i+1, j-1 {
+			v.Data[i], v.Data[j] = v.Data[j], v.Data[i]
+		}
+	case *core.ArrayFloat:
+		for i, j := 0, len(v.Data)-1; i < j; i, j = i+1, j-1 {
+			v.Data[i], v.Data[j] = v.Data[j], v.Data[i]
+		}
+	case *core.Array:
+		for i, j := 0, len(v.Data)-1; i < j; i, j = i+1, j-1 {
+			v.Data[i], v.Data[j] = v.Data[j], v.Data[i]
+		}
 	}
 	return arr
 }
 
 // SliceºArr extracts some consecutive elements from within an array.
-func SliceºArr(rt *Runtime, arr *core.Array, start, end int64) (*core.Array, error) {
-	ret := core.NewArray()
-	if start < 0 || end > int64(len(arr.Data)) {
-		return ret, fmt.Errorf(ErrorText(ErrInvalidParam))
+func SliceºArr(rt *Runtime, arr core.Indexer, start, end int64) (core.Indexer, error) {
+	length := int64(arr.Len())
+	if start < 0 || end > length {
+		return newValue(rt, arrType(arr)).(core.Indexer), fmt.Errorf(ErrorText(ErrInvalidParam))
 	}
 	if end == 0 {
-		end = int64(len(arr.Data))
+		end = length
+	}
+	switch v := arr.(type) {
+	case *core.ArrayInt:
+		ret := core.NewArrayInt()
+		ret.Data = append(ret.Data, v.Data[start:end]...)
+		return ret, nil
+	case *core.ArrayFloat:
+		ret := core.NewArrayFloat()
+		ret.Data = append(ret.Data, v.Data[start:end]...)
+		return ret, nil
 	}
+	ret := core.NewArray()
 	for ; start < end; start++ {
 		var ptr interface{}
-		CopyVar(rt, &ptr, arr.Data[start])
+		CopyVar(rt, &ptr, arr.(*core.Array).Data[start])
 		ret.Data = append(ret.Data, ptr)
 	}
 	return ret, nil
 }
 
+// arrType returns the type of the array
+func arrType(arr core.Indexer) int {
+	switch arr.(type) {
+	case *core.ArrayInt:
+		return core.TYPEARRINT
+	case *core.ArrayFloat:
+		return core.TYPEARRFLOAT
+	}
+	return core.TYPEARR
+}
+
 // SortºArr sorts an array of strings
 func SortºArr(value *core.Array) *core.Array {
 	sort.Sort(value)
 	return value
 }
+
+// SortºArrInt sorts an array of integers
+func SortºArrInt(value *core.ArrayInt) *core.ArrayInt {
+	sort.Sort(value)
+	return value
+}
+
+// SortºArrFloat sorts an array of floats
+func SortºArrFloat(value *core.ArrayFloat) *core.ArrayFloat {
+	sort.Sort(value)
+	return value
+}
+
+// fnSorter sorts an array with fn comparator
+type fnSorter struct {
+	rt  *Runtime
+	arr core.Indexer
+	fn  *Fn
+	err error
+}
+
+func (s *fnSorter) Len() int {
+	return s.arr.Len()
+}
+
+func (s *fnSorter) Swap(i, j int) {
+	s.arr.(sort.Interface).Swap(i, j)
+}
+
+func (s *fnSorter) Less(i, j int) bool {
+	if s.err != nil {
+		return false
+	}
+	left, _ := s.arr.GetIndex(int64(i))
+	right, _ := s.arr.GetIndex(int64(j))
+	var less interface{}
+	if less, s.err = s.rt.callFn(s.fn, left, right); s.err != nil {
+		return false
+	}
+	return less.(int64) != 0
+}
+
+// SortºArrFn sorts an array with fn comparator
+func SortºArrFn(rt *Runtime, arr core.Indexer, fn *Fn) (core.Indexer, error) {
+	sorter := &fnSorter{rt: rt, arr: arr, fn: fn}
+	sort.Sort(sorter)
+	return arr, sorter.err
+}
+
+// SortStableºArr sorts an array with fn comparator keeping the order of equal items
+func SortStableºArr(rt *Runtime, arr core.Indexer, fn *Fn) (core.Indexer, error) {
+	sorter := &fnSorter{rt: rt, arr: arr, fn: fn}
+	sort.Stable(sorter)
+	return arr, sorter.err
+}
+
+// keySorter sorts an array by the keys of its items
+type keySorter struct {
+	arr  core.Indexer
+	keys []interface{}
+}
+
+func (s *keySorter) Len() int {
+	return len(s.keys)
+}
+
+func (s *keySorter) Swap(i, j int) {
+	s.arr.(sort.Interface).Swap(i, j)
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
+
+func (s *keySorter) Less(i, j int) bool {
+	switch v := s.keys[i].(type) {
+	case int64:
+		return v < s.keys[j].(int64)
+	case float64:
+		return v < s.keys[j].(float64)
+	}
+	return s.keys[i].(string) < s.keys[j].(string)
+}
+
+// SortByºArr sorts an array by the keys which fn returns for the items
+func SortByºArr(rt *Runtime, arr core.Indexer, fn *Fn) (core.Indexer, error) {
+	sorter := &keySorter{arr: arr, keys: make([]interface{}, arr.Len())}
+	for i := range sorter.keys {
+		item, _ := arr.GetIndex(int64(i))
+		key, err := rt.callFn(fn, item)
+		if err != nil {
+			return arr, err
+		}
+		sorter.keys[i] = key
+	}
+	sort.Stable(sorter)
+	return arr, nil
+}
+
+// MapºArr appends the results of fn for the items of the array to the new array
+func MapºArr(rt *Runtime, arr core.Indexer, fn *Fn, ret core.Indexer) (core.Indexer, error) {
+	for i := 0; i < arr.Len(); i++ {
+		item, _ := arr.GetIndex(int64(i))
+		value, err := rt.callFn(fn, item)
+		if err != nil {
+			return ret, err
+		}
+		AssignAddºArrAny(ret, value)
+	}
+	return ret, nil
+}
+
+// FilterºArr returns a new array with the items for which fn returns true
+func FilterºArr(rt *Runtime, arr core.Indexer, fn *Fn) (core.Indexer, error) {
+	ret := newValue(rt, arrType(arr)).(core.Indexer)
+	for i := 0; i < arr.Len(); i++ {
+		item, _ := arr.GetIndex(int64(i))
+		ok, err := rt.callFn(fn, item)
+		if err != nil {
+			return ret, err
+		}
+		if ok.(int64) != 0 {
+			var ptr interface{}
+			CopyVar(rt, &ptr, item)
+			AssignAddºArrAny(ret, ptr)
+		}
+	}
+	return ret, nil
+}
+
+// reduceArr calls fn for the accumulator and every item of the array
+func reduceArr(rt *Runtime, arr core.Indexer, fn *Fn, acc interface{}) (interface{}, error) {
+	var err error
+	for i := 0; i < arr.Len(); i++ {
+		item, _ := arr.GetIndex(int64(i))
+		if acc, err = rt.callFn(fn, acc, item); err != nil {
+			return nil, err
+		}
+	}
+	return acc, nil
+}
+
+// ReduceºArrInt reduces an array to an integer value
+func ReduceºArrInt(rt *Runtime, arr core.Indexer, fn *Fn, init int64) (int64, error) {
+	acc, err := reduceArr(rt, arr, fn, init)
+	if err != nil {
+		return 0, err
+	}
+	return acc.(int64), nil
+}
+
+// ReduceºArrFloat reduces an array to a float value
+func ReduceºArrFloat(rt *Runtime, arr core.Indexer, fn *Fn, init float64) (float64, error) {
+	acc, err := reduceArr(rt, arr, fn, init)
+	if err != nil {
+		return 0, err
+	}
+	return acc.(float64), nil
+}
+
+// ReduceºArrStr reduces an array to a string
+func ReduceºArrStr(rt *Runtime, arr core.Indexer, fn *Fn, init string) (string, error) {
+	acc, err := reduceArr(rt, arr, fn, init)
+	if err != nil {
+		return ``, err
+	}
+	return acc.(string), nil
+}
+
+// FindIndexºArr returns the index of the first item for which fn returns true or -1
+func FindIndexºArr(rt *Runtime, arr core.Indexer, fn *Fn) (int64, error) {
+	for i := 0; i < arr.Len(); i++ {
+		item, _ := arr.GetIndex(int64(i))
+		ok, err := rt.callFn(fn, item)
+		if err != nil {
+			return -1, err
+		}
+		if ok.(int64) != 0 {
+			return int64(i), nil
+		}
+	}
+	return -1, nil
+}
+
+// AnyºArr returns true if fn returns true for any item of the array
+func AnyºArr(rt *Runtime, arr core.Indexer, fn *Fn) (int64, error) {
+	ind, err := FindIndexºArr(rt, arr, fn)
+	if ind < 0 {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// AllºArr returns true if fn returns true for all items of the array
+func AllºArr(rt *Runtime, arr core.Indexer, fn *Fn) (int64, error) {
+	for i := 0; i < arr.Len(); i++ {
+		item, _ := arr.GetIndex(int64(i))
+		ok, err := rt.callFn(fn, item)
+		if err != nil {
+			return 0, err
+		}
+		if ok.(int64) == 0 {
+			return 0, nil
+		}
+	}
+	return 1, nil
+}
+
+// GroupByºArr groups the items of the array by the keys which fn returns
+func GroupByºArr(rt *Runtime, arr core.Indexer, fn *Fn) (*core.Map, error) {
+	ret := core.NewMap()
+	for i := 0; i < arr.Len(); i++ {
+		item, _ := arr.GetIndex(int64(i))
+		key, err := rt.callFn(fn, item)
+		if err != nil {
+			return ret, err
+		}
+		group, ok := ret.Data[key.(string)]
+		if !ok {
+			group = newValue(rt, arrType(arr))
+			ret.SetIndex(key, group)
+		}
+		var ptr interface{}
+		CopyVar(rt, &ptr, item)
+		AssignAddºArrAny(group, ptr)
+	}
+	return ret, nil
+}
@@ -0,0 +1,236 @@
+// Copyright 2019 Alexey Krivonogov. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	"github.com/gentee/gentee/core"
+)
+
+// selectCase is a case of select statement
+type selectCase struct {
+	Kind    int
+	Chan    *core.Chan
+	Value   interface{}
+	Timeout int64
+}
+
+// waitChan blocks the thread until one of the cases is ready. It returns -1 if the waiting
+// has been interrupted because the thread has been closed or another thread has failed.
+// The suspended thread doesn't send or receive values until it is resumed.
+// timer is true if one of the cases is a timer and the waiting cannot be a deadlock.
+// It is a deadlock if all threads are waiting in waitChan during the whole tick.
+func (rt *Runtime) waitChan(cases []reflect.SelectCase, timer bool) (int, reflect.Value, bool, int) {
+	vm := rt.Owner
+	if !timer {
+		atomic.AddInt64(&vm.Blocked, 1)
+		defer func() {
+			atomic.AddInt64(&vm.Blocked, -1)
+			atomic.AddInt64(&vm.Unblocked, 1)
+		}()
+	}
+	unblocked := int64(-1)
+	count := len(cases)
+	tick := time.NewTicker(time.Duration(SleepStep) * time.Millisecond)
+	defer tick.Stop()
+	cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv,
+		Chan: reflect.ValueOf(rt.Thread.Chan)},
+		reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(tick.C)})
+	active := make([]reflect.SelectCase, len(cases))
+	for {
+		if rt.status() == ThClosed || (rt.ThreadID == 0 && len(rt.Owner.ChError) > 0) {
+			return -1, reflect.Value{}, false, 0
+		}
+		copy(active, cases)
+		if rt.status() == ThPaused {
+			for i := 0; i < count; i++ {
+				active[i].Chan = reflect.Value{}
+			}
+		}
+		chosen, recv, ok := reflect.Select(active)
+		switch chosen - count {
+		case 0:
+			if !ok {
+				cases[count].Chan = reflect.Value{}
+				continue
+			}
+			switch recv.Int() {
+			case ThCmdResume, ThCmdContinue:
+				rt.setStatus(ThWork)
+			case ThCmdClose:
+				rt.setStatus(ThClosed)
+			}
+		case 1:
+			if timer || rt.ThreadID != 0 || rt.status() == ThPaused {
+				continue
+			}
+			threads := vm.threadCount()
+			if atomic.LoadInt64(&vm.Blocked) <= threads {
+				unblocked = -1
+				continue
+			}
+			// the main thread and the other threads are blocked, the check is repeated if any
+			// thread has been unblocked since the previous tick
+			if last := atomic.LoadInt64(&vm.Unblocked); threads > 0 && last != unblocked {
+				unblocked = last
+				continue
+			}
+			// there are not other threads which can send or receive values
+			chosen, recv, ok = reflect.Select(append(cases[:count:count],
+				reflect.SelectCase{Dir: reflect.SelectDefault}))
+			if chosen == count {
+				return -1, reflect.Value{}, false, ErrDeadlock
+			}
+			return chosen, recv, ok, 0
+		default:
+			return chosen, recv, ok, 0
+		}
+	}
+}
+
+// chanSend sends the value to the channel
+func (rt *Runtime) chanSend(ch *core.Chan, value interface{}) int {
+	if ch.IsClosed() {
+		return ErrChanClosed
+	}
+	chosen, _, _, errID := rt.waitChan([]reflect.SelectCase{
+		{Dir: reflect.SelectSend, Chan: reflect.ValueOf(ch.Data), Send: reflect.ValueOf(&value).Elem()},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ch.Done)},
+	}, false)
+	if chosen == 1 {
+		errID = ErrChanClosed
+	}
+	return errID
+}
+
+// chanReceive receives the value from the channel. It returns false if the channel
+// has been closed and there are not values in the buffer.
+func (rt *Runtime) chanReceive(ch *core.Chan) (interface{}, bool, int) {
+	chosen, recv, _, errID := rt.waitChan([]reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ch.Data)},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ch.Done)},
+	}, false)
+	switch chosen {
+	case -1:
+		return nil, false, errID
+	case 1:
+		select {
+		case value := <-ch.Data:
+			return value, true, 0
+		default:
+			return nil, false, 0
+		}
+	}
+	return recv.Interface(), true, 0
+}
+
+// selectChan waits until one of the cases is ready and returns its index and the received value.
+// The value is nil if the channel has been closed. It returns -1 if the waiting has been interrupted.
+func (rt *Runtime) selectChan(sel []selectCase) (int, interface{}, int) {
+	var (
+		timer     bool
+		isDefault bool
+	)
+	cases := make([]reflect.SelectCase, 0, 2*len(sel))
+	// index contains the indexes of sel items for cases, done is true for Done channels
+	index := make([]int, 0, 2*len(sel))
+	done := make([]bool, 0, 2*len(sel))
+	for i, item := range sel {
+		switch item.Kind {
+		case core.SelReceive:
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv,
+				Chan: reflect.ValueOf(item.Chan.Data)})
+		case core.SelSend:
+			if item.Chan.IsClosed() {
+				return -1, nil, ErrChanClosed
+			}
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectSend,
+				Chan: reflect.ValueOf(item.Chan.Data), Send: reflect.ValueOf(&sel[i].Value).Elem()})
+		case core.SelTimeout:
+			t := time.NewTimer(time.Duration(item.Timeout) * time.Millisecond)
+			defer t.Stop()
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv,
+				Chan: reflect.ValueOf(t.C)})
+			index = append(index, i)
+			done = append(done, false)
+			timer = true
+			continue
+		case core.SelDefault:
+			isDefault = true
+			continue
+		}
+		// Done is closed when the channel has been closed
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv,
+			Chan: reflect.ValueOf(item.Chan.Done)})
+		index = append(index, i, i)
+		done = append(done, false, true)
+	}
+	var (
+		chosen int
+		recv   reflect.Value
+		errID  int
+	)
+	if isDefault {
+		chosen, recv, _ = reflect.Select(append(cases, reflect.SelectCase{Dir: reflect.SelectDefault}))
+		if chosen == len(cases) {
+			for i, item := range sel {
+				if item.Kind == core.SelDefault {
+					return i, nil, 0
+				}
+			}
+		}
+	} else if chosen, recv, _, errID = rt.waitChan(cases, timer); chosen < 0 {
+		return -1, nil, errID
+	}
+	i := index[chosen]
+	switch sel[i].Kind {
+	case core.SelTimeout:
+		return i, nil, 0
+	case core.SelSend:
+		if done[chosen] {
+			return -1, nil, ErrChanClosed
+		}
+		return i, nil, 0
+	}
+	if done[chosen] {
+		select {
+		case value := <-sel[i].Chan.Data:
+			return i, value, 0
+		default:
+			return i, nil, 0
+		}
+	}
+	return i, recv.Interface(), 0
+}
+
+// AssignºChanInt creates a new channel with the specified size of the buffer
+func AssignºChanInt(ptr interface{}, value interface{}) (interface{}, error) {
+	if value.(int64) < 0 {
+		return nil, fmt.Errorf(ErrorText(ErrInvalidParam))
+	}
+	return core.NewChan(int(value.(int64))), nil
+}
+
+// CloseºChan closes the channel
+func CloseºChan(ch *core.Chan) error {
+	if !ch.Close() {
+		return fmt.Errorf(ErrorText(ErrChanClosed))
+	}
+	return nil
+}
+
+// CapºChan returns the size of the buffer of the channel
+func CapºChan(ch *core.Chan) int64 {
+	return int64(cap(ch.Data))
+}
+
+// LenºChan returns the count of values in the buffer of the channel
+func LenºChan(ch *core.Chan) int64 {
+	return int64(len(ch.Data))
+}
@@ -0,0 +1,269 @@
+// Copyright 2019 Alexey Krivonogov. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gentee/gentee/core"
+)
+
+// cmdOption is the declaration of the command-line option
+type cmdOption struct {
+	Name     string
+	Short    string
+	Type     string // int, float, bool, str or arr
+	Default  string
+	Desc     string
+	Required bool
+}
+
+// optValue converts the string to the value of the option type
+func (opt *cmdOption) optValue(value string) (interface{}, error) {
+	switch opt.Type {
+	case `int`:
+		return strconv.ParseInt(value, 10, 64)
+	case `float`:
+		return strconv.ParseFloat(value, 64)
+	case `bool`:
+		if len(value) == 0 {
+			return false, nil
+		}
+		return strconv.ParseBool(value)
+	case `arr`:
+		ret := core.NewArray()
+		if len(value) > 0 {
+			for _, item := range strings.Split(value, `,`) {
+				ret.Data = append(ret.Data, item)
+			}
+		}
+		return ret, nil
+	}
+	return value, nil
+}
+
+// isOption returns true if the command-line parameter is an option. A lone dash is
+// a positional parameter which usually means the standard input.
+func isOption(arg string) bool {
+	return len(arg) > 1 && arg[0] == '-'
+}
+
+// needValue returns true if the next command-line parameter is the value of the option
+func (opt *cmdOption) needValue(next string) bool {
+	if !isOption(next) {
+		return true
+	}
+	if opt.Type == `int` || opt.Type == `float` {
+		_, err := strconv.ParseFloat(next, 64)
+		return err == nil
+	}
+	return false
+}
+
+// addOption checks and appends the declaration of the option
+func (rt *Runtime) addOption(opt *cmdOption) error {
+	opt.Name = strings.TrimLeft(opt.Name, `-`)
+	opt.Short = strings.TrimLeft(opt.Short, `-`)
+	switch opt.Type {
+	case `int`, `float`, `bool`, `str`, `arr`:
+	default:
+		return fmt.Errorf(ErrorText(ErrOptionDef), opt.Name)
+	}
+	if len(opt.Name) == 0 {
+		return fmt.Errorf(ErrorText(ErrOptionDef), opt.Name)
+	}
+	if _, err := opt.optValue(opt.Default); err != nil {
+		return fmt.Errorf(ErrorText(ErrOptionDef), opt.Name)
+	}
+	vm := rt.Owner
+	vm.SyncMutex.Lock()
+	defer vm.SyncMutex.Unlock()
+	for _, item := range vm.Options {
+		if item.Name == opt.Name || item.Short == opt.Name || (len(opt.Short) > 0 &&
+			(item.Name == opt.Short || item.Short == opt.Short)) {
+			return fmt.Errorf(ErrorText(ErrOptionDef), opt.Name)
+		}
+	}
+	vm.Options = append(vm.Options, opt)
+	return nil
+}
+
+// findOption returns the option by its name or short name
+func findOption(options []*cmdOption, name string) *cmdOption {
+	for _, opt := range options {
+		if opt.Name == name || (len(opt.Short) > 0 && opt.Short == name) {
+			return opt
+		}
+	}
+	return nil
+}
+
+// getOptions returns the copy of the declared options
+func (rt *Runtime) getOptions() []*cmdOption {
+	rt.Owner.SyncMutex.Lock()
+	defer rt.Owner.SyncMutex.Unlock()
+	return append([]*cmdOption{}, rt.Owner.Options...)
+}
+
+// ArgOptionºStrStrStrStrStr declares the command-line option with the default value
+func ArgOptionºStrStrStrStrStr(rt *Runtime, name, short, optType, def, desc string) error {
+	return rt.addOption(&cmdOption{Name: name, Short: short, Type: optType, Default: def,
+		Desc: desc})
+}
+
+// ArgRequiredºStrStrStrStr declares the required command-line option
+func ArgRequiredºStrStrStrStr(rt *Runtime, name, short, optType, desc string) error {
+	return rt.addOption(&cmdOption{Name: name, Short: short, Type: optType, Desc: desc,
+		Required: true})
+}
+
+// ArgUsage returns the usage text of the declared command-line options
+func ArgUsage(rt *Runtime) string {
+	name := strings.TrimSuffix(filepath.Base(rt.Owner.Exec.Path), filepath.Ext(rt.Owner.Exec.Path))
+	lines := []string{fmt.Sprintf("Usage: %s [options] [arguments]\n\nOptions:", name)}
+	var (
+		left  []string
+		width int
+	)
+	options := rt.getOptions()
+	if findOption(options, `help`) == nil {
+		help := &cmdOption{Name: `help`, Type: `bool`, Desc: `print this help`}
+		if findOption(options, `h`) == nil {
+			help.Short = `h`
+		}
+		options = append(options, help)
+	}
+	for _, opt := range options {
+		item := `    `
+		if len(opt.Short) > 0 {
+			item = `-` + opt.Short + `, `
+		}
+		item += `--` + opt.Name
+		if opt.Type != `bool` {
+			item += ` ` + opt.Type
+		}
+		if len(item) > width {
+			width = len(item)
+		}
+		left = append(left, item)
+	}
+	for i, opt := range options {
+		desc := opt.Desc
+		if opt.Required {
+			desc += ` (required)`
+		} else if len(opt.Default) > 0 {
+			desc += fmt.Sprintf(` (default: %s)`, opt.Default)
+		}
+		lines = append(lines, fmt.Sprintf(`  %-*s  %s`, width, left[i], desc))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// parseCmdLine parses the command-line parameters according to the options. It returns
+// the values of the specified options and the rest parameters.
+func parseCmdLine(rt *Runtime, options []*cmdOption) (map[string]interface{}, []string, error) {
+	var (
+		tail []string
+		i    int
+	)
+	cmdLine := rt.Owner.Settings.CmdLine
+	values := make(map[string]interface{})
+	for ; i < len(cmdLine); i++ {
+		arg := cmdLine[i]
+		if !isOption(arg) {
+			tail = append(tail, arg)
+			continue
+		}
+		if arg == `--` {
+			// the rest parameters are not options
+			i++
+			break
+		}
+		name := strings.TrimLeft(arg, `-`)
+		var (
+			value    string
+			hasValue bool
+		)
+		if off := strings.IndexAny(name, `=:`); off >= 0 {
+			name, value, hasValue = name[:off], name[off+1:], true
+			if len(value) > 1 && value[0] == value[len(value)-1] &&
+				(value[0] == '"' || value[0] == '\'') {
+				value = value[1 : len(value)-1]
+			}
+		}
+		opt := findOption(options, name)
+		if opt == nil {
+			if name == `help` || name == `h` {
+				fmt.Print(ArgUsage(rt))
+				return nil, nil, ExitºInt(rt, 0)
+			}
+			return nil, nil, fmt.Errorf(ErrorText(ErrOptionUnknown), arg)
+		}
+		switch opt.Type {
+		case `bool`:
+			if !hasValue {
+				value = `true`
+			}
+		case `arr`:
+			list, ok := values[opt.Name].(*core.Array)
+			if !ok {
+				list = core.NewArray()
+				values[opt.Name] = list
+			}
+			if hasValue {
+				list.Data = append(list.Data, value)
+			}
+			for ; !hasValue && i+1 < len(cmdLine) && !isOption(cmdLine[i+1]); i++ {
+				list.Data = append(list.Data, cmdLine[i+1])
+			}
+			continue
+		default:
+			if !hasValue {
+				if i+1 >= len(cmdLine) || !opt.needValue(cmdLine[i+1]) {
+					return nil, nil, fmt.Errorf(ErrorText(ErrOptionValue), opt.Name)
+				}
+				i++
+				value = cmdLine[i]
+			}
+		}
+		v, err := opt.optValue(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf(ErrorText(ErrOptionValue), opt.Name)
+		}
+		values[opt.Name] = v
+	}
+	return values, append(tail, cmdLine[i:]...), nil
+}
+
+// ParseArgs parses the command-line parameters according to the declared options.
+// It returns the object with the values of the options and the rest parameters in '_' item.
+// If there is --help option then the usage text is printed and the script is finished.
+func ParseArgs(rt *Runtime) (*core.Obj, error) {
+	options := rt.getOptions()
+	values, tail, err := parseCmdLine(rt, options)
+	if err != nil {
+		return nil, err
+	}
+	ret := core.NewMap()
+	for _, opt := range options {
+		v, ok := values[opt.Name]
+		if !ok {
+			if opt.Required {
+				return nil, fmt.Errorf(ErrorText(ErrOptionRequired), opt.Name)
+			}
+			v, _ = opt.optValue(opt.Default)
+		}
+		ret.SetIndex(opt.Name, v)
+	}
+	rest := core.NewArray()
+	for _, item := range tail {
+		rest.Data = append(rest.Data, item)
+	}
+	ret.SetIndex(`_`, rest)
+	return objType(ret)
+}
@@ -67,6 +67,46 @@ const (
 	ErrObjNil
 	// ErrObjType is returned when the value has incompatible type
 	ErrObjType
+	// ErrStack is returned when the size of the stack has exceeded the limit
+	ErrStack
+	// ErrMemory is returned when the memory usage has exceeded the limit
+	ErrMemory
+	// ErrGas is returned when the limit of gas has been exceeded
+	ErrGas
+	// ErrIfaceEmpty is returned in case of calling the method of undefined interface variable
+	ErrIfaceEmpty
+	// ErrChanClosed is returned when the value is sent to the closed channel
+	ErrChanClosed
+	// ErrDeadlock is returned when the main thread waits for the channel and there are no other threads
+	ErrDeadlock
+	// ErrNotLocked is returned when the unlocked mutex is unlocked
+	ErrNotLocked
+	// ErrCron is returned when cron expression is invalid
+	ErrCron
+	// ErrSignal is returned when the signal is unknown
+	ErrSignal
+	// ErrStopped is returned when the script has been stopped by the signal
+	ErrStopped
+	// ErrExit is the message of ExitError
+	ErrExit
+	// ErrOptionDef is returned when the declaration of the command-line option is invalid
+	ErrOptionDef
+	// ErrOptionUnknown is returned when the command-line option has not been declared
+	ErrOptionUnknown
+	// ErrOptionValue is returned when the value of the command-line option is invalid
+	ErrOptionValue
+	// ErrOptionRequired is returned when the required command-line option is missing
+	ErrOptionRequired
+	// ErrEnvRequired is returned when the environment variable required by the header is not defined
+	ErrEnvRequired
+	// ErrTaskDef is returned when the declaration of the task is invalid
+	ErrTaskDef
+	// ErrTaskUnknown is returned when the task has not been declared
+	ErrTaskUnknown
+	// ErrTaskCycle is returned when the task depends on itself
+	ErrTaskCycle
+	// ErrLockOwner is returned when the mutex is unlocked by another thread
+	ErrLockOwner
 
 	// ErrEmbedded means golang error in embedded functions
 	ErrEmbedded = 254
@@ -88,6 +128,7 @@ type RuntimeError struct {
 	ID      int
 	Message string
 	Trace   []TraceInfo
+	Cause   *RuntimeError // the wrapped error
 }
 
 func (re *RuntimeError) Error() string {
@@ -98,35 +139,64 @@ func (re *RuntimeError) Error() string {
 	return ErrFormat(si.Path, si.Line, si.Pos, re.Message)
 }
 
+// ExitError is returned when the script has been finished by Exit function
+type ExitError struct {
+	Code int // the exit code of the script
+}
+
+func (ee *ExitError) Error() string {
+	return fmt.Sprintf(ErrorText(ErrExit), ee.Code)
+}
+
 var (
 	errText = map[int]string{
-		ErrRunIndex:     `invalid name of Run`,
-		ErrDepth:        `maximum depth of recursion has been reached`,
-		ErrDivZero:      `divided by zero`,
-		ErrCycle:        `maximum cycle count has been reached`,
-		ErrShift:        `right operand of shift cannot be less than zero`,
-		ErrStrToInt:     `converting string to integer is invalid`,
-		ErrStrToFloat:   `converting string to float is invalid`,
-		ErrEmptyCommand: `empty $ command`,
-		ErrQuoteCommand: `unclosed quotation mark in $ command`,
-		ErrIndexOut:     `index out of range`,
-		ErrMapIndex:     `there is not key in the map`,
-		ErrAssignment:   `there is a recursive or self assignment`,
-		ErrUndefined:    `undefined value`,
-		ErrByteOut:      `byte value is out of range`,
-		ErrInvalidParam: `invalid value of parameter(s)`,
-		ErrNotRun:       `there is not run function`,
-		ErrFnEmpty:      `fn variable has not been defined`,
-		ErrThreadIndex:  `invalid thread`,
-		ErrThreadClosed: `thread has been closed`,
-		ErrPlatform:     `unsupported platform`,
-		ErrObjValue:     `value of the object has wrong type`,
-		ErrCustom:       `invalid custom declaration`,
-		ErrCRC:          `different CRC of stdlib or custom functions`,
-		ErrMainThread:   `%s must be called in the main thread`,
-		ErrThread:       `%s cannot be called in the main thread`,
-		ErrObjNil:       `obj is undefined (nil)`,
-		ErrObjType:      `type is incompatible to object`,
+		ErrRunIndex:       `invalid name of Run`,
+		ErrDepth:          `maximum depth of recursion has been reached`,
+		ErrDivZero:        `divided by zero`,
+		ErrCycle:          `maximum cycle count has been reached`,
+		ErrShift:          `right operand of shift cannot be less than zero`,
+		ErrStrToInt:       `converting string to integer is invalid`,
+		ErrStrToFloat:     `converting string to float is invalid`,
+		ErrEmptyCommand:   `empty $ command`,
+		ErrQuoteCommand:   `unclosed quotation mark in $ command`,
+		ErrIndexOut:       `index out of range`,
+		ErrMapIndex:       `there is not key in the map`,
+		ErrAssignment:     `there is a recursive or self assignment`,
+		ErrUndefined:      `undefined value`,
+		ErrByteOut:        `byte value is out of range`,
+		ErrInvalidParam:   `invalid value of parameter(s)`,
+		ErrNotRun:         `there is not run function`,
+		ErrFnEmpty:        `fn variable has not been defined`,
+		ErrThreadIndex:    `invalid thread`,
+		ErrThreadClosed:   `thread has been closed`,
+		ErrPlatform:       `unsupported platform`,
+		ErrObjValue:       `value of the object has wrong type`,
+		ErrCustom:         `invalid custom declaration`,
+		ErrCRC:            `different CRC of stdlib or custom functions`,
+		ErrMainThread:     `%s must be called in the main thread`,
+		ErrThread:         `%s cannot be called in the main thread`,
+		ErrObjNil:         `obj is undefined (nil)`,
+		ErrObjType:        `type is incompatible to object`,
+		ErrStack:          `stack overflow`,
+		ErrMemory:         `memory limit has been exceeded`,
+		ErrGas:            `gas limit has been exceeded`,
+		ErrIfaceEmpty:     `interface variable has not been defined`,
+		ErrChanClosed:     `channel has been closed`,
+		ErrDeadlock:       `deadlock: there are no other threads to use the channel or mutex`,
+		ErrNotLocked:      `mutex %s is not locked`,
+		ErrCron:           `invalid cron expression '%s'`,
+		ErrSignal:         `unknown signal %s`,
+		ErrStopped:        `script has been stopped by signal %s`,
+		ErrExit:           `exit status %d`,
+		ErrOptionDef:      `invalid declaration of option %s`,
+		ErrOptionUnknown:  `unknown option %s`,
+		ErrOptionValue:    `invalid value of option %s`,
+		ErrOptionRequired: `option %s is required`,
+		ErrEnvRequired:    `environment variable %s is not defined`,
+		ErrTaskDef:        `invalid declaration of task %s`,
+		ErrTaskUnknown:    `unknown task %s`,
+		ErrTaskCycle:      `cyclic dependency of task %s`,
+		ErrLockOwner:      `mutex %s is locked by another thread`,
 
 		ErrRuntime: `you have found a runtime bug. Let us know, please`,
 	}
@@ -174,10 +244,19 @@ func GetTrace(rt *Runtime, pos int64) []TraceInfo {
 		}
 	}
 	for _, call := range rt.Calls {
-		if !call.IsFunc {
+		if call.IsFunc {
+			newTrace(call.Offset)
+		}
+		if call.Tail == nil {
 			continue
 		}
-		newTrace(call.Offset)
+		for i, offset := range call.Tail.Offsets {
+			if i == TAILTRACE-1 && call.Tail.Elided > 0 {
+				ret = append(ret, TraceInfo{Entry: entry,
+					Func: fmt.Sprintf(`... %d tail calls`, call.Tail.Elided)})
+			}
+			newTrace(offset)
+		}
 	}
 	if pos >= 0 {
 		newTrace(int32(pos))
@@ -234,6 +313,8 @@ func runtimeError(rt *Runtime, pos int64, err interface{}, labels ...interface{}
 	var (
 		errText string
 		idError int
+		trace   []TraceInfo
+		cause   *RuntimeError
 	)
 	switch v := err.(type) {
 	case int:
@@ -242,6 +323,10 @@ func runtimeError(rt *Runtime, pos int64, err interface{}, labels ...interface{}
 	case *RuntimeError:
 		errText = v.Message
 		idError = v.ID
+		// the error from fn called by the embedded function or the wrapped error keeps
+		// the trace of the original error
+		trace = v.Trace
+		cause = v.Cause
 	case error:
 		errText = v.Error()
 		idError = ErrEmbedded
@@ -249,9 +334,13 @@ func runtimeError(rt *Runtime, pos int64, err interface{}, labels ...interface{}
 	for _, item := range labels {
 		errText += fmt.Sprintf(` [%v]`, item)
 	}
+	if len(trace) == 0 {
+		trace = GetTrace(rt, pos)
+	}
 	return &RuntimeError{
 		ID:      idError,
 		Message: errText,
-		Trace:   GetTrace(rt, pos),
+		Trace:   trace,
+		Cause:   cause,
 	}
 }
@@ -21,6 +21,34 @@ func errorºIntStr(code int64, text string, pars ...interface{}) error {
 	}
 }
 
+// errorºErrStr throws the error which wraps another error and adds the text to its message
+func errorºErrStr(cause *RuntimeError, text string) error {
+	return &RuntimeError{
+		ID:      cause.ID,
+		Message: text + `: ` + cause.Message,
+		Trace:   cause.Trace,
+		Cause:   cause,
+	}
+}
+
+// ErrCause returns the original error of the wrapped error
+func ErrCause(err *RuntimeError) *RuntimeError {
+	for err.Cause != nil {
+		err = err.Cause
+	}
+	return err
+}
+
+// ErrIs returns true if the error or one of the wrapped errors has the specified id
+func ErrIs(err *RuntimeError, id int64) int64 {
+	for ; err != nil; err = err.Cause {
+		if int64(err.ID) == id {
+			return 1
+		}
+	}
+	return 0
+}
+
 // ErrID returns the id of the error
 func ErrID(err *RuntimeError) int64 {
 	return int64(err.ID)
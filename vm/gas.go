@@ -0,0 +1,99 @@
+// Copyright 2019 Alexey Krivonogov. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"sync/atomic"
+
+	"github.com/gentee/gentee/core"
+)
+
+const (
+	// GASBATCH is the amount of gas which a thread spends before it updates the shared counter
+	GASBATCH = 1024
+	// GASEMBED is the default weight of embedded functions
+	GASEMBED = 10
+	// GASCALL is the weight of function calls
+	GASCALL = 5
+	// GASTHREAD is the weight of starting a new thread
+	GASTHREAD = 100
+	// GASIO is the weight of file, network and process functions
+	GASIO = 1000
+	// GASBYTES is the size of processed data which costs one unit of gas
+	GASBYTES = 64
+)
+
+var (
+	// gasOp contains the weights of commands. EMBED is calculated separately.
+	gasOp [0x1000]uint8
+	// embedGas contains the weights of the expensive embedded functions
+	embedGas = map[string]uint32{
+		`CopyFile`: GASIO, `Download`: GASIO, `HTTPGet`: GASIO, `HTTPPage`: GASIO,
+		`Md5File`: GASIO, `Open`: GASIO, `OpenWith`: GASIO, `ReadDir`: GASIO,
+		`ReadFile`: GASIO, `Sha256File`: GASIO, `sysRun`: GASIO, `WriteFile`: GASIO,
+		`FindRegExp`: 50, `Match`: 50, `ReplaceRegExp`: 50, `Sort`: 50,
+	}
+)
+
+func init() {
+	for i := range gasOp {
+		gasOp[i] = 1
+	}
+	gasOp[core.EMBED] = 0
+	gasOp[core.INITVARS] = 2
+	gasOp[core.INITOBJ] = GASCALL
+	gasOp[core.ARRAY] = GASCALL
+	gasOp[core.CLOSURE] = GASCALL
+	gasOp[core.CALLBYID] = GASCALL
+	gasOp[core.TAILCALL] = GASCALL
+	gasOp[core.LOCAL] = GASCALL
+	gasOp[core.GOBYID] = GASTHREAD
+	for i, embed := range EmbedFuncs {
+		if gas, ok := embedGas[embed.Name]; ok && embed.Gas == 0 {
+			EmbedFuncs[i].Gas = gas
+		}
+	}
+}
+
+// embedWeight returns the weight of the call of the embedded function
+func embedWeight(embed *core.Embed, size int64) int64 {
+	gas := int64(embed.Gas)
+	if gas == 0 {
+		gas = GASEMBED
+	}
+	return gas + size/GASBYTES
+}
+
+// spendGas adds the gas spent by the thread to the shared counter and reserves the next batch
+// of gas. The batch never exceeds the rest of the limit, so threads cannot spend more gas than
+// the limit together. It returns ErrGas if the limit of gas has been exceeded.
+func (rt *Runtime) spendGas() int {
+	vm := rt.Owner
+	// the reserved batch has already been added, so only the overspent gas is added
+	total := atomic.AddUint64(&vm.Gas, uint64(-rt.GasLeft))
+	rt.GasLeft = 0
+	if vm.Settings.Gas > 0 && total > vm.Settings.Gas {
+		return ErrGas
+	}
+	for {
+		batch := uint64(GASBATCH)
+		if vm.Settings.Gas > 0 {
+			if rest := vm.Settings.Gas - total; rest < batch {
+				batch = rest
+			}
+		}
+		if atomic.CompareAndSwapUint64(&vm.Gas, total, total+batch) {
+			rt.GasLeft = int64(batch)
+			return 0
+		}
+		total = atomic.LoadUint64(&vm.Gas)
+	}
+}
+
+// releaseGas returns the unused reserved gas of the thread to the shared counter
+func (rt *Runtime) releaseGas() {
+	atomic.AddUint64(&rt.Owner.Gas, uint64(-rt.GasLeft))
+	rt.GasLeft = 0
+}
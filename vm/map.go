@@ -46,3 +46,140 @@ func KeyºMapInt(pmap *core.Map, index int64) (string, error) {
 	}
 	return pmap.Keys[index], nil
 }
+
+// MapºMap returns a new map with the results of fn for the keys and values of the map
+func MapºMap(rt *Runtime, pmap *core.Map, fn *Fn) (*core.Map, error) {
+	ret := core.NewMap()
+	for i := 0; i < len(pmap.Keys); i++ {
+		key := pmap.Keys[i]
+		value, err := rt.callFn(fn, key, pmap.Data[key])
+		if err != nil {
+			return ret, err
+		}
+		ret.SetIndex(key, value)
+	}
+	return ret, nil
+}
+
+// FilterºMap returns a new map with the items for which fn returns true
+func FilterºMap(rt *Runtime, pmap *core.Map, fn *Fn) (*core.Map, error) {
+	ret := core.NewMap()
+	for i := 0; i < len(pmap.Keys); i++ {
+		key := pmap.Keys[i]
+		ok, err := rt.callFn(fn, key, pmap.Data[key])
+		if err != nil {
+			return ret, err
+		}
+		if ok.(int64) != 0 {
+			var ptr interface{}
+			CopyVar(rt, &ptr, pmap.Data[key])
+			ret.SetIndex(key, ptr)
+		}
+	}
+	return ret, nil
+}
+
+// reduceMap calls fn for the accumulator and every key and value of the map
+func reduceMap(rt *Runtime, pmap *core.Map, fn *Fn, acc interface{}) (interface{}, error) {
+	var err error
+	for i := 0; i < len(pmap.Keys); i++ {
+		key := pmap.Keys[i]
+		if acc, err = rt.callFn(fn, acc, key, pmap.Data[key]); err != nil {
+			return nil, err
+		}
+	}
+	return acc, nil
+}
+
+// ReduceºMapInt reduces a map to an integer value
+func ReduceºMapInt(rt *Runtime, pmap *core.Map, fn *Fn, init int64) (int64, error) {
+	acc, err := reduceMap(rt, pmap, fn, init)
+	if err != nil {
+		return 0, err
+	}
+	return acc.(int64), nil
+}
+
+// ReduceºMapFloat reduces a map to a float value
+func ReduceºMapFloat(rt *Runtime, pmap *core.Map, fn *Fn, init float64) (float64, error) {
+	acc, err := reduceMap(rt, pmap, fn, init)
+	if err != nil {
+		return 0, err
+	}
+	return acc.(float64), nil
+}
+
+// ReduceºMapStr reduces a map to a string
+func ReduceºMapStr(rt *Runtime, pmap *core.Map, fn *Fn, init string) (string, error) {
+	acc, err := reduceMap(rt, pmap, fn, init)
+	if err != nil {
+		return ``, err
+	}
+	return acc.(string), nil
+}
+
+// findMap returns the key of the first item for which fn returns true
+func findMap(rt *Runtime, pmap *core.Map, fn *Fn) (string, bool, error) {
+	for i := 0; i < len(pmap.Keys); i++ {
+		key := pmap.Keys[i]
+		ok, err := rt.callFn(fn, key, pmap.Data[key])
+		if err != nil {
+			return ``, false, err
+		}
+		if ok.(int64) != 0 {
+			return key, true, nil
+		}
+	}
+	return ``, false, nil
+}
+
+// FindIndexºMap returns the key of the first item for which fn returns true or an empty string
+func FindIndexºMap(rt *Runtime, pmap *core.Map, fn *Fn) (string, error) {
+	key, _, err := findMap(rt, pmap, fn)
+	return key, err
+}
+
+// AnyºMap returns true if fn returns true for any item of the map
+func AnyºMap(rt *Runtime, pmap *core.Map, fn *Fn) (int64, error) {
+	_, ok, err := findMap(rt, pmap, fn)
+	if !ok {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// AllºMap returns true if fn returns true for all items of the map
+func AllºMap(rt *Runtime, pmap *core.Map, fn *Fn) (int64, error) {
+	for i := 0; i < len(pmap.Keys); i++ {
+		key := pmap.Keys[i]
+		ok, err := rt.callFn(fn, key, pmap.Data[key])
+		if err != nil {
+			return 0, err
+		}
+		if ok.(int64) == 0 {
+			return 0, nil
+		}
+	}
+	return 1, nil
+}
+
+// GroupByºMap groups the items of the map by the keys which fn returns
+func GroupByºMap(rt *Runtime, pmap *core.Map, fn *Fn) (*core.Map, error) {
+	ret := core.NewMap()
+	for i := 0; i < len(pmap.Keys); i++ {
+		key := pmap.Keys[i]
+		groupKey, err := rt.callFn(fn, key, pmap.Data[key])
+		if err != nil {
+			return ret, err
+		}
+		group, ok := ret.Data[groupKey.(string)]
+		if !ok {
+			group = core.NewMap()
+			ret.SetIndex(groupKey, group)
+		}
+		var ptr interface{}
+		CopyVar(rt, &ptr, pmap.Data[key])
+		group.(*core.Map).SetIndex(key, ptr)
+	}
+	return ret, nil
+}
@@ -0,0 +1,291 @@
+// Copyright 2019 Alexey Krivonogov. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/gentee/gentee/core"
+)
+
+const (
+	// MEMSTEP is the size of allocations between measurements of the used memory
+	MEMSTEP = 1 << 20
+	// MEMMINSTEP is the minimum step of the measurement if the memory usage is close to the limit
+	MEMMINSTEP = 1 << 10
+	// MEMITEM is the size of one item of arrays, maps and structures
+	MEMITEM = 16
+	// MEMKEY is the additional size of one key of maps
+	MEMKEY = 48
+)
+
+// Stats contains the statistics of the script execution
+type Stats struct {
+	PeakMemory int64  // approximate peak memory usage in bytes
+	Gas        uint64 // consumed gas
+}
+
+// allocSize returns the approximate size of the allocated value without nested items
+func allocSize(v interface{}) (size int64) {
+	switch val := v.(type) {
+	case string:
+		size = int64(len(val))
+	case *core.Array:
+		if val != nil {
+			size = int64(len(val.Data)) * MEMITEM
+		}
+	case *core.ArrayInt:
+		if val != nil {
+			size = int64(len(val.Data)) * 8
+		}
+	case *core.ArrayFloat:
+		if val != nil {
+			size = int64(len(val.Data)) * 8
+		}
+	case *core.Buffer:
+		if val != nil {
+			size = int64(len(val.Data))
+		}
+	case *core.Set:
+		if val != nil {
+			size = int64(len(val.Data)) * 8
+		}
+	case *core.Map:
+		if val != nil {
+			size = int64(len(val.Keys)) * (MEMITEM + MEMKEY)
+		}
+	case *Struct:
+		if val != nil {
+			size = int64(len(val.Values)) * MEMITEM
+		}
+	case *Fn:
+		size = MEMITEM
+		if val != nil && val.Captured != nil {
+			size += int64(len(*val.Captured)) * MEMITEM
+		}
+	case *core.Obj, *core.Range, *core.Chan:
+		size = MEMITEM
+	}
+	return
+}
+
+// capSize returns the size of the allocated capacity of the array, the buffer or the set
+func capSize(v interface{}) (size int64) {
+	switch val := v.(type) {
+	case *core.Array:
+		if val != nil {
+			size = int64(cap(val.Data)) * MEMITEM
+		}
+	case *core.ArrayInt:
+		if val != nil {
+			size = int64(cap(val.Data)) * 8
+		}
+	case *core.ArrayFloat:
+		if val != nil {
+			size = int64(cap(val.Data)) * 8
+		}
+	case *core.Buffer:
+		if val != nil {
+			size = int64(cap(val.Data))
+		}
+	case *core.Set:
+		if val != nil {
+			size = int64(cap(val.Data)) * 8
+		}
+	}
+	return
+}
+
+// growSize returns the size of the memory which has been allocated by the container
+// since its capacity was prev
+func growSize(v interface{}, prev int64) int64 {
+	if size := capSize(v) - prev; size > 0 {
+		return size
+	}
+	return 0
+}
+
+// memSize returns the approximate size of the value with all nested items.
+// seen contains the values which have already been calculated.
+func memSize(v interface{}, seen map[interface{}]bool) (size int64) {
+	switch v.(type) {
+	case string:
+		return int64(len(v.(string)))
+	case *core.Array, *core.ArrayInt, *core.ArrayFloat, *core.Buffer, *core.Set, *core.Map,
+		*core.Obj, *Struct, *Fn:
+		if seen[v] {
+			return
+		}
+		seen[v] = true
+	default:
+		return
+	}
+	switch val := v.(type) {
+	case *core.Array:
+		if val != nil {
+			size = int64(cap(val.Data)) * MEMITEM
+			for _, item := range val.Data {
+				size += memSize(item, seen)
+			}
+		}
+	case *core.ArrayInt:
+		if val != nil {
+			size = int64(cap(val.Data)) * 8
+		}
+	case *core.ArrayFloat:
+		if val != nil {
+			size = int64(cap(val.Data)) * 8
+		}
+	case *core.Buffer:
+		if val != nil {
+			size = int64(cap(val.Data))
+		}
+	case *core.Set:
+		if val != nil {
+			size = int64(cap(val.Data)) * 8
+		}
+	case *core.Map:
+		if val != nil {
+			for key, item := range val.Data {
+				size += MEMITEM + MEMKEY + 2*int64(len(key)) + memSize(item, seen)
+			}
+		}
+	case *core.Obj:
+		if val != nil {
+			size = MEMITEM + memSize(val.Data, seen)
+		}
+	case *Struct:
+		if val != nil {
+			size = int64(len(val.Values)) * MEMITEM
+			for _, item := range val.Values {
+				size += memSize(item, seen)
+			}
+		}
+	case *Fn:
+		if val != nil {
+			size = MEMITEM
+			if val.Captured != nil {
+				for _, item := range *val.Captured {
+					size += MEMITEM + memSize(item.Value, seen)
+				}
+			}
+		}
+	}
+	return
+}
+
+// measureMemory calculates the memory used by the stacks and the variables of the thread
+func (rt *Runtime) measureMemory(top *Call) int64 {
+	size := int64(len(rt.SInt)+len(rt.SFloat))*8 + int64(len(rt.SStr)+len(rt.SAny))*MEMITEM +
+		int64(cap(rt.Calls))*int64(unsafe.Sizeof(Call{}))
+	for _, s := range rt.SStr[:top.Str] {
+		size += int64(len(s))
+	}
+	seen := make(map[interface{}]bool)
+	for _, v := range rt.SAny[:top.Any] {
+		size += memSize(v, seen)
+	}
+	if rt.Optional != nil {
+		for _, opt := range *rt.Optional {
+			size += memSize(opt.Value, seen)
+		}
+	}
+	return size
+}
+
+// allocMemory takes into account the allocated memory. The used memory is measured
+// again if there have been enough allocations since the last measurement.
+// It returns ErrMemory if the memory usage exceeds the limit.
+func (rt *Runtime) allocMemory(top *Call, size int64) int {
+	rt.Allocated += size
+	if rt.Allocated < rt.MemStep {
+		return 0
+	}
+	vm := rt.Owner
+	live := rt.measureMemory(top)
+	total := atomic.AddInt64(&vm.Memory, live-rt.Memory)
+	rt.Memory = live
+	rt.Allocated = 0
+	for {
+		peak := atomic.LoadInt64(&vm.PeakMemory)
+		if total <= peak || atomic.CompareAndSwapInt64(&vm.PeakMemory, peak, total) {
+			break
+		}
+	}
+	rt.MemStep = live
+	if rt.MemStep < MEMSTEP {
+		rt.MemStep = MEMSTEP
+	}
+	if vm.Settings.MaxMemory > 0 {
+		if total > vm.Settings.MaxMemory {
+			return ErrMemory
+		}
+		if rest := vm.Settings.MaxMemory - total; rest < rt.MemStep {
+			rt.MemStep = rest
+			if rt.MemStep < MEMMINSTEP {
+				rt.MemStep = MEMMINSTEP
+			}
+		}
+	}
+	return 0
+}
+
+// pushCall appends the frame to the blocks stack. The size of the blocks stack is limited with
+// Settings.Depth and with the memory limit. If there is no memory limit then the blocks stack
+// cannot take more than CALLSMEMORY bytes.
+func (rt *Runtime) pushCall(top *Call, call Call) int {
+	vm := rt.Owner
+	prev := cap(rt.Calls)
+	rt.Calls = append(rt.Calls, call)
+	if vm.Settings.Depth > 0 && uint32(len(rt.Calls)) >= vm.Settings.Depth {
+		return ErrDepth
+	}
+	if vm.Settings.MaxMemory == 0 && int64(len(rt.Calls))*int64(unsafe.Sizeof(Call{})) > CALLSMEMORY {
+		return ErrDepth
+	}
+	if prev == cap(rt.Calls) {
+		return 0
+	}
+	return rt.allocMemory(top, int64(cap(rt.Calls)-prev)*int64(unsafe.Sizeof(Call{})))
+}
+
+// depthLimit returns the effective limit of the blocks stack. If Settings.Depth is 0 then
+// the limit is calculated from the memory which the blocks stack can take.
+func (vm *VM) depthLimit() int64 {
+	if vm.Settings.Depth > 0 {
+		return int64(vm.Settings.Depth)
+	}
+	size := int64(CALLSMEMORY)
+	if vm.Settings.MaxMemory > 0 {
+		size = vm.Settings.MaxMemory
+	}
+	return size / int64(unsafe.Sizeof(Call{}))
+}
+
+// initMemory sets the initial memory usage of the thread
+func (rt *Runtime) initMemory() {
+	rt.Memory = rt.measureMemory(&Call{})
+	rt.MemStep = MEMMINSTEP
+	atomic.AddInt64(&rt.Owner.Memory, rt.Memory)
+}
+
+// freeMemory removes the memory of the finished thread from the total usage
+func (rt *Runtime) freeMemory() {
+	atomic.AddInt64(&rt.Owner.Memory, -rt.Memory)
+	rt.Memory = 0
+}
+
+// peakMemory returns the peak memory usage of the virtual machine
+func (vm *VM) peakMemory() int64 {
+	peak := atomic.LoadInt64(&vm.PeakMemory)
+	if len(vm.Runtimes) > 0 {
+		// allocations after the last measurement of the main thread
+		if current := atomic.LoadInt64(&vm.Memory) + vm.Runtimes[0].Allocated; current > peak {
+			peak = current
+		}
+	}
+	return peak
+}
@@ -0,0 +1,208 @@
+// Copyright 2019 Alexey Krivonogov. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// namedLock is a read-write mutex. It is based on channels so the waiting threads
+// can be suspended or terminated.
+type namedLock struct {
+	write   chan struct{} // it contains an item while the mutex is locked for writing or reading
+	gate    chan struct{} // it lets only one reader or waiting writer pass
+	mutex   sync.Mutex    // it protects readers and writer
+	readers map[int64]int64
+	count   int64 // count of read locks
+	writer  bool
+	owner   int64 // id of the thread which has locked the mutex for writing
+}
+
+func (rt *Runtime) getLock(name string) *namedLock {
+	rt.Owner.SyncMutex.Lock()
+	defer rt.Owner.SyncMutex.Unlock()
+	lock := rt.Owner.Locks[name]
+	if lock == nil {
+		lock = &namedLock{
+			write:   make(chan struct{}, 1),
+			gate:    make(chan struct{}, 1),
+			readers: make(map[int64]int64),
+		}
+		rt.Owner.Locks[name] = lock
+	}
+	return lock
+}
+
+// releaseLocks unlocks the mutexes which have been locked by the finished thread
+func (vm *VM) releaseLocks(threadID int64) {
+	vm.SyncMutex.Lock()
+	defer vm.SyncMutex.Unlock()
+	for _, lock := range vm.Locks {
+		lock.mutex.Lock()
+		if lock.writer && lock.owner == threadID {
+			lock.writer = false
+			<-lock.write
+		}
+		if count := lock.readers[threadID]; count > 0 {
+			delete(lock.readers, threadID)
+			if lock.count -= count; lock.count == 0 {
+				<-lock.write
+			}
+		}
+		lock.mutex.Unlock()
+	}
+}
+
+func (rt *Runtime) getCounter(name string) *int64 {
+	rt.Owner.SyncMutex.Lock()
+	defer rt.Owner.SyncMutex.Unlock()
+	counter := rt.Owner.Counters[name]
+	if counter == nil {
+		counter = new(int64)
+		rt.Owner.Counters[name] = counter
+	}
+	return counter
+}
+
+// acquire puts an item into the channel. It returns false if the timeout has expired.
+// The negative timeout means waiting without time limit.
+func (rt *Runtime) acquire(ch chan struct{}, timeout int64) (bool, error) {
+	cases := []reflect.SelectCase{{Dir: reflect.SelectSend, Chan: reflect.ValueOf(ch),
+		Send: reflect.ValueOf(struct{}{})}}
+	if timeout >= 0 {
+		timer := time.NewTimer(time.Duration(timeout) * time.Millisecond)
+		defer timer.Stop()
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv,
+			Chan: reflect.ValueOf(timer.C)})
+	}
+	chosen, _, _, errID := rt.waitChan(cases, timeout >= 0)
+	switch chosen {
+	case 0:
+		return true, nil
+	case -1:
+		if errID == 0 {
+			errID = ErrThreadClosed
+		}
+		return false, fmt.Errorf(ErrorText(errID))
+	}
+	return false, nil
+}
+
+func (rt *Runtime) lock(name string, timeout int64) (bool, error) {
+	lock := rt.getLock(name)
+	start := time.Now()
+	// the writer holds the gate while it is waiting, so new readers cannot lock the mutex
+	ok, err := rt.acquire(lock.gate, timeout)
+	if !ok {
+		return ok, err
+	}
+	defer func() { <-lock.gate }()
+	if timeout > 0 {
+		if timeout -= int64(time.Since(start) / time.Millisecond); timeout < 0 {
+			timeout = 0
+		}
+	}
+	if ok, err = rt.acquire(lock.write, timeout); ok {
+		lock.mutex.Lock()
+		lock.writer = true
+		lock.owner = rt.ThreadID
+		lock.mutex.Unlock()
+	}
+	return ok, err
+}
+
+// LockºStr locks the named mutex for writing
+func LockºStr(rt *Runtime, name string) error {
+	_, err := rt.lock(name, -1)
+	return err
+}
+
+// TryLockºStrInt tries to lock the named mutex for writing during timeout milliseconds
+func TryLockºStrInt(rt *Runtime, name string, timeout int64) (int64, error) {
+	if timeout < 0 {
+		timeout = 0
+	}
+	ok, err := rt.lock(name, timeout)
+	if ok {
+		return 1, nil
+	}
+	return 0, err
+}
+
+// UnlockºStr unlocks the named mutex locked for writing
+func UnlockºStr(rt *Runtime, name string) error {
+	lock := rt.getLock(name)
+	lock.mutex.Lock()
+	defer lock.mutex.Unlock()
+	if !lock.writer {
+		return fmt.Errorf(ErrorText(ErrNotLocked), name)
+	}
+	if lock.owner != rt.ThreadID {
+		return fmt.Errorf(ErrorText(ErrLockOwner), name)
+	}
+	lock.writer = false
+	<-lock.write
+	return nil
+}
+
+// RLockºStr locks the named mutex for reading
+func RLockºStr(rt *Runtime, name string) error {
+	lock := rt.getLock(name)
+	if _, err := rt.acquire(lock.gate, -1); err != nil {
+		return err
+	}
+	defer func() { <-lock.gate }()
+	lock.mutex.Lock()
+	if lock.count > 0 {
+		lock.count++
+		lock.readers[rt.ThreadID]++
+		lock.mutex.Unlock()
+		return nil
+	}
+	lock.mutex.Unlock()
+	// the first reader locks the mutex for the other readers
+	if _, err := rt.acquire(lock.write, -1); err != nil {
+		return err
+	}
+	lock.mutex.Lock()
+	lock.count++
+	lock.readers[rt.ThreadID]++
+	lock.mutex.Unlock()
+	return nil
+}
+
+// RUnlockºStr unlocks the named mutex locked for reading
+func RUnlockºStr(rt *Runtime, name string) error {
+	lock := rt.getLock(name)
+	lock.mutex.Lock()
+	defer lock.mutex.Unlock()
+	if lock.readers[rt.ThreadID] == 0 {
+		return fmt.Errorf(ErrorText(ErrNotLocked), name)
+	}
+	if lock.readers[rt.ThreadID]--; lock.readers[rt.ThreadID] == 0 {
+		delete(lock.readers, rt.ThreadID)
+	}
+	if lock.count--; lock.count == 0 {
+		<-lock.write
+	}
+	return nil
+}
+
+// AtomicAddºStrInt atomically adds delta to the named counter and returns the new value
+func AtomicAddºStrInt(rt *Runtime, name string, delta int64) int64 {
+	return atomic.AddInt64(rt.getCounter(name), delta)
+}
+
+// CompareAndSwapºStrIntInt atomically replaces the value of the named counter if it equals old
+func CompareAndSwapºStrIntInt(rt *Runtime, name string, old, new int64) int64 {
+	if atomic.CompareAndSwapInt64(rt.getCounter(name), old, new) {
+		return 1
+	}
+	return 0
+}
@@ -213,6 +213,20 @@ func objType(val interface{}) (*core.Obj, error) {
 			data.Data = append(data.Data, iobj)
 		}
 		obj.Data = data
+	case *core.ArrayInt:
+		data := core.NewArray()
+		data.Data = make([]interface{}, len(v.Data))
+		for i, item := range v.Data {
+			data.Data[i] = &core.Obj{Data: item}
+		}
+		obj.Data = data
+	case *core.ArrayFloat:
+		data := core.NewArray()
+		data.Data = make([]interface{}, len(v.Data))
+		for i, item := range v.Data {
+			data.Data[i] = &core.Obj{Data: item}
+		}
+		obj.Data = data
 	case *core.Map:
 		data := core.NewMap()
 		data.Keys = make([]string, len(v.Keys))
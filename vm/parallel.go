@@ -0,0 +1,124 @@
+// Copyright 2019 Alexey Krivonogov. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+
+	"github.com/gentee/gentee/core"
+)
+
+// parallel calls fn for the items in the worker threads and returns the results in the order
+// of the items. The first error closes the workers and the remaining items are skipped.
+// If workers is less than 1 then the count of workers equals the number of CPUs.
+func (rt *Runtime) parallel(items core.Indexer, workers int64, fn *Fn) ([]interface{}, error) {
+	if fn == nil || fn.Func == 0 {
+		return nil, fmt.Errorf(ErrorText(ErrFnEmpty))
+	}
+	count := items.Len()
+	results := make([]interface{}, count)
+	if workers < 1 {
+		workers = int64(runtime.NumCPU())
+	}
+	if workers > int64(count) {
+		workers = int64(count)
+	}
+	threads := make([]*Runtime, workers)
+	for i := range threads {
+		if threads[i] = rt.Owner.newThread(ThQueue); threads[i] == nil {
+			return nil, fmt.Errorf(ErrorText(ErrThreadClosed))
+		}
+	}
+	var (
+		mutex sync.Mutex
+		wg    sync.WaitGroup
+		next  int
+		first error
+	)
+	// job returns the index of the next item or -1 if the work is over
+	job := func() int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if first != nil || next >= count {
+			return -1
+		}
+		next++
+		return next - 1
+	}
+	fail := func(err error) {
+		mutex.Lock()
+		cancel := first == nil
+		if cancel {
+			first = err
+		}
+		mutex.Unlock()
+		if cancel {
+			rt.Owner.closeThreads(threads)
+		}
+	}
+	wg.Add(len(threads))
+	for _, thread := range threads {
+		var value interface{}
+		CopyVar(rt, &value, fn)
+		copyCaptured(rt, value.(*Fn))
+		go func(thread *Runtime, fn *Fn) {
+			thread.setStatus(ThWork)
+			var err error
+			for i := job(); i >= 0 && thread.status() != ThClosed; i = job() {
+				item, _ := items.GetIndex(int64(i))
+				if results[i], err = thread.callFn(fn, item); err != nil {
+					fail(err)
+					break
+				}
+			}
+			rt.Owner.endThread(thread, nil, err, false)
+			wg.Done()
+		}(thread, value.(*Fn))
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	chosen, _, _, errID := rt.waitChan([]reflect.SelectCase{{Dir: reflect.SelectRecv,
+		Chan: reflect.ValueOf(done)}}, false)
+	if chosen < 0 {
+		rt.Owner.closeThreads(threads)
+		if errID == 0 {
+			errID = ErrThreadClosed
+		}
+		return nil, fmt.Errorf(ErrorText(errID))
+	}
+	return results, first
+}
+
+// ParallelForºArr calls fn for the items of the array in the worker threads
+func ParallelForºArr(rt *Runtime, arr core.Indexer, workers int64, fn *Fn) error {
+	_, err := rt.parallel(arr, workers, fn)
+	return err
+}
+
+// ParallelForºRange calls fn for the values of the range in the worker threads
+func ParallelForºRange(rt *Runtime, r *core.Range, workers int64, fn *Fn) error {
+	_, err := rt.parallel(r, workers, fn)
+	return err
+}
+
+// ParallelMapºArr appends the results of fn for the items of the array to the new array.
+// fn is called in the worker threads.
+func ParallelMapºArr(rt *Runtime, arr core.Indexer, workers int64, fn *Fn,
+	ret core.Indexer) (core.Indexer, error) {
+	results, err := rt.parallel(arr, workers, fn)
+	if err != nil {
+		return ret, err
+	}
+	for _, value := range results {
+		AssignAddºArrAny(ret, value)
+	}
+	return ret, nil
+}
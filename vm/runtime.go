@@ -29,6 +29,11 @@ type indexInfo struct {
 }
 
 func (rt *Runtime) Run(i int64) (result interface{}, err error) {
+	return rt.run(i, Call{})
+}
+
+// run executes the bytecode from the specified position with the specified top of stacks
+func (rt *Runtime) run(i int64, top Call) (result interface{}, err error) {
 	var (
 		iInfo    indexInfo
 		tmpInt   int64
@@ -37,20 +42,46 @@ func (rt *Runtime) Run(i int64) (result interface{}, err error) {
 		count    int
 	)
 
-	top := Call{}
 	code := rt.Owner.Exec.Code
 	end := int64(len(code))
+	// gas is metered only if there is the limit or the statistics is required
+	metered := rt.Owner.Settings.Gas > 0 || rt.Owner.Settings.Stats != nil
 
-	errHandle := func(pos int64, errPar interface{}, pars ...interface{}) {
+	// toFinally jumps to finally code if the blocks starting from the index are deleted
+	// and one of them has finally code. The current command is continued after finally code.
+	toFinally := func(from int, pending int32) bool {
+		k := len(rt.Calls) - 1
+		for ; k >= from; k-- {
+			if rt.Calls[k].Flags&core.BlFinally != 0 {
+				break
+			}
+		}
+		if k < from {
+			return false
+		}
+		rt.Calls = rt.Calls[:k+1]
+		rt.Calls[k].Pending = pending
+		i = int64(rt.Calls[k].Offset + rt.Calls[k].Finally)
+		return true
+	}
+	// throw passes err to the nearest catch block
+	throw := func() {
 		k := len(rt.Calls) - 1
 		for ; k > 0; k-- {
 			if rt.Calls[k].Flags&core.BlTry != 0 {
 				break
 			}
 		}
-		//fmt.Println(`errHandle`, pos, rt.Owner.Exec.Pos)
-		err = runtimeError(rt, pos, errPar, pars...)
-		if k <= 0 {
+		if k <= 0 || rt.Thread.Closing {
+			// the error of the closed thread cannot be caught
+			k = -1
+		}
+		if toFinally(k+1, -1) {
+			rt.SAny[top.Any] = err
+			top.Any++
+			return
+		}
+		if k < 0 {
 			i = end + 1
 			return
 		}
@@ -62,9 +93,28 @@ func (rt *Runtime) Run(i int64) (result interface{}, err error) {
 		top.Any++
 		rt.ParCount = 1
 	}
+	errHandle := func(pos int64, errPar interface{}, pars ...interface{}) {
+		//fmt.Println(`errHandle`, pos, rt.Owner.Exec.Pos)
+		err = runtimeError(rt, pos, errPar, pars...)
+		throw()
+	}
 
 main:
 	for i < end {
+		if metered {
+			if rt.GasLeft -= int64(gasOp[code[i]&0xfff]); rt.GasLeft < 0 && rt.spendGas() != 0 {
+				// it cannot be caught by try
+				err = runtimeError(rt, i, ErrGas)
+				break main
+			}
+		}
+		if int(top.Int+STACKGAP) > len(rt.SInt) || int(top.Float+STACKGAP) > len(rt.SFloat) ||
+			int(top.Str+STACKGAP) > len(rt.SStr) || int(top.Any+STACKGAP) > len(rt.SAny) {
+			if errID := rt.growStacks(&top, STACKGAP); errID != 0 {
+				errHandle(i, errID)
+				continue
+			}
+		}
 		switch code[i] & 0x0fff {
 		case core.PUSH32:
 			i++
@@ -86,6 +136,50 @@ main:
 			i++
 			rt.SAny[top.Any] = &Fn{Func: int32(code[i])}
 			top.Any++
+		case core.METHOD:
+			pstruct := rt.SAny[top.Any-1-int32(code[i]>>16)].(*Struct)
+			i++
+			id, ok := rt.Owner.Exec.Methods[core.MethodKey(pstruct.Type.ID, int32(code[i]))]
+			if !ok {
+				errHandle(i, ErrIfaceEmpty)
+				continue main
+			}
+			rt.SAny[top.Any] = &Fn{Func: id}
+			top.Any++
+		case core.CLOSURE:
+			count := int64(code[i] >> 16)
+			captured := make([]OptValue, count)
+			for j := count; j > 0; j-- {
+				var value interface{}
+				itype := int(code[i+j+1] >> 16)
+				switch itype & 0xf {
+				case core.STACKINT:
+					top.Int--
+					value = rt.SInt[top.Int]
+				case core.STACKSTR:
+					top.Str--
+					value = rt.SStr[top.Str]
+				case core.STACKFLOAT:
+					top.Float--
+					value = rt.SFloat[top.Float]
+				case core.STACKANY:
+					top.Any--
+					value = rt.SAny[top.Any]
+				}
+				captured[j-1] = OptValue{
+					Var:   int32(code[i+j+1] & 0xffff),
+					Type:  itype,
+					Value: value,
+				}
+			}
+			fn := &Fn{Func: int32(code[i+1]), Captured: &captured}
+			rt.SAny[top.Any] = fn
+			top.Any++
+			if errID := rt.allocMemory(&top, allocSize(fn)); errID != 0 {
+				errHandle(i, errID)
+				continue main
+			}
+			i += count + 1
 		case core.ADD:
 			top.Int--
 			rt.SInt[top.Int-1] += rt.SInt[top.Int]
@@ -208,6 +302,10 @@ main:
 		case core.ADDSTR:
 			top.Str--
 			rt.SStr[top.Str-1] += rt.SStr[top.Str]
+			if errID := rt.allocMemory(&top, int64(len(rt.SStr[top.Str-1]))); errID != 0 {
+				errHandle(i, errID)
+				continue main
+			}
 		case core.EQSTR:
 			top.Str -= 2
 			if rt.SStr[top.Str] == rt.SStr[top.Str+1] {
@@ -299,12 +397,33 @@ main:
 				ptr = rt.SAny[root]
 			}
 			if count == 1 {
-				if prange, isrange := ptr.(*core.Range); isrange {
-					if prange.From < prange.To {
-						rt.SInt[top.Int-1] = prange.From + rt.SInt[top.Int-1]
+				switch v := ptr.(type) {
+				case *core.Range:
+					if v.From < v.To {
+						rt.SInt[top.Int-1] = v.From + rt.SInt[top.Int-1]
 					} else {
-						rt.SInt[top.Int-1] = prange.From - rt.SInt[top.Int-1]
+						rt.SInt[top.Int-1] = v.From - rt.SInt[top.Int-1]
+					}
+					i += 2
+					continue main
+				case *core.ArrayInt:
+					ind := rt.SInt[top.Int-1]
+					if ind < 0 || ind >= int64(len(v.Data)) {
+						errHandle(i+1, ErrIndexOut)
+						continue main
+					}
+					rt.SInt[top.Int-1] = v.Data[ind]
+					i += 2
+					continue main
+				case *core.ArrayFloat:
+					top.Int--
+					ind := rt.SInt[top.Int]
+					if ind < 0 || ind >= int64(len(v.Data)) {
+						errHandle(i+1, ErrIndexOut)
+						continue main
 					}
+					rt.SFloat[top.Float] = v.Data[ind]
+					top.Float++
 					i += 2
 					continue main
 				}
@@ -420,6 +539,31 @@ main:
 				fmt.Println(`root index`, typeVar)
 			}
 			obj.Index = root
+			if count == 1 && code[i+3]&0xffff == core.ASSIGN {
+				// fast path for arr.int and arr.float
+				switch v := ptr.(type) {
+				case *core.ArrayInt:
+					top.Int--
+					ind := rt.SInt[top.Int]
+					if ind < 0 || ind >= int64(len(v.Data)) {
+						errHandle(i+2, ErrIndexOut)
+						continue main
+					}
+					v.Data[ind] = rt.SInt[top.Int-1]
+					i += 4
+					continue main
+				case *core.ArrayFloat:
+					top.Int--
+					ind := rt.SInt[top.Int]
+					if ind < 0 || ind >= int64(len(v.Data)) {
+						errHandle(i+2, ErrIndexOut)
+						continue main
+					}
+					v.Data[ind] = rt.SFloat[top.Float-1]
+					i += 4
+					continue main
+				}
+			}
 			if count > 0 {
 				i++
 				for ind := 0; ind < count; ind++ {
@@ -454,8 +598,8 @@ main:
 						if !ok {
 							if key, ok := obj.Index.(string); ok {
 								value = newValue(rt, typeRet)
-								if ptr.(core.Indexer).SetIndex(key, value) != 0 {
-									errHandle(i, ErrIndexOut)
+								if errID := ptr.(core.Indexer).SetIndex(key, value); errID != 0 {
+									errHandle(i, errID)
 									continue main
 									//return nil, runtimeError(rt, i, ErrIndexOut)
 								}
@@ -505,6 +649,10 @@ main:
 					}
 					if assign == core.ASSIGN {
 						CopyVar(rt, &rt.SAny[root], rt.SAny[top.Any-1])
+						if errID := rt.allocMemory(&top, allocSize(rt.SAny[root])); errID != 0 {
+							errHandle(i, errID)
+							continue main
+						}
 					} else {
 						rt.SAny[root] = rt.SAny[top.Any-1]
 					}
@@ -530,6 +678,10 @@ main:
 				fmt.Printf("iValue %x\n", rightType)
 			}
 			obj = &iInfo.Objects[count]
+			var (
+				allocated int64
+				embed     *core.Embed
+			)
 			if assign == core.ASSIGN || assign == core.ASSIGNPTR &&
 				core.Bcode(obj.Type) == rightType {
 				switch v := ptr.(type) {
@@ -547,11 +699,21 @@ main:
 					if assign == core.ASSIGN {
 						CopyVar(rt, &ptr, iValue)
 						iValue = ptr
+						allocated = allocSize(iValue)
 					}
 				}
 			} else {
+				switch iValue.(type) {
+				case string:
+					// the appended string is shared
+					allocated = MEMITEM
+				default:
+					allocated = allocSize(iValue)
+				}
+				prev := capSize(ptr)
 				if assign >= core.EMBEDFUNC {
 					assign -= core.EMBEDFUNC
+					embed = &EmbedFuncs[assign]
 					switch v := ptr.(type) {
 					case *int64:
 						iValue, err = EmbedFuncs[assign].Func.(core.AssignIntFunc)(
@@ -573,6 +735,24 @@ main:
 					errHandle(i, err)
 					continue main
 				}
+				switch ptr.(type) {
+				case *string:
+					// the result is a new string
+					allocated = allocSize(iValue)
+				case *int64, *float64:
+					allocated = 0
+				default:
+					allocated += growSize(ptr, prev)
+				}
+			}
+			if embed != nil && metered {
+				rt.GasLeft -= embedWeight(embed, allocated)
+			}
+			if allocated > 0 {
+				if errID := rt.allocMemory(&top, allocated); errID != 0 {
+					errHandle(i, errID)
+					continue main
+				}
 			}
 			if count > 0 || typeVar&0xf == core.STACKANY {
 				if count > 0 && iInfo.Objects[count-1].Type == core.TYPESTR {
@@ -613,10 +793,17 @@ main:
 							errHandle(i, ErrAssignment)
 							continue main
 						}
+						prev := capSize(obj.Obj)
 						if errID := obj.Obj.(core.Indexer).SetIndex(obj.Index, iValue); errID != 0 {
 							errHandle(i, errID)
 							continue main
 						}
+						if size := growSize(obj.Obj, prev); size > 0 {
+							if errID := rt.allocMemory(&top, size); errID != 0 {
+								errHandle(i, errID)
+								continue main
+							}
+						}
 					}
 				}
 			}
@@ -732,7 +919,7 @@ main:
 			i++
 		case core.INITVARS:
 			var (
-				breakJmp, continueJmp, tryJmp, recoverJmp, retryJmp int32
+				breakJmp, continueJmp, tryJmp, recoverJmp, retryJmp, finallyJmp int32
 			)
 			flags := int16(code[i] >> 16)
 			pos := i
@@ -757,6 +944,10 @@ main:
 				i++
 				retryJmp = int32(code[i])
 			}
+			if flags&core.BlFinally != 0 {
+				i++
+				finallyJmp = int32(code[i])
+			}
 			var prevTop Call
 			curTop := top
 			if rt.ParCount > 0 {
@@ -773,6 +964,10 @@ main:
 				}
 				i++
 				varCount := int32(code[i] & 0xffff)
+				if errID := rt.growStacks(&top, varCount+STACKGAP); errID != 0 {
+					errHandle(pos, errID)
+					continue main
+				}
 				for k := int32(0); k < varCount; k++ {
 					i++
 					varType := int(code[i])
@@ -838,6 +1033,7 @@ main:
 				Try:      tryJmp,
 				Recover:  recoverJmp,
 				Retry:    retryJmp,
+				Finally:  finallyJmp,
 			})
 
 			//			fmt.Println(`INIT OK`, rt.SInt[:top.Int], rt.SAny[:top.Any])
@@ -850,6 +1046,10 @@ main:
 				rt.SAny[j] = nil
 			}
 			// fmt.Println(`DELVARS`, rt.Calls)
+		case core.BOX:
+			rt.SAny[rt.Calls[len(rt.Calls)-1].Any+int32(code[i]>>16)] = &core.Array{
+				Data: []interface{}{newValue(rt, int(code[i+1]))}}
+			i++
 		case core.OPTPARS:
 			count := int64(code[i] >> 16)
 			optional := make([]OptValue, count)
@@ -912,6 +1112,18 @@ main:
 					}
 				}
 				rt.SAny[top.Any] = parr
+			case core.TYPEARRINT:
+				parr := core.NewArrayInt()
+				parr.Data = make([]int64, count)
+				top.Int -= int32(count)
+				copy(parr.Data, rt.SInt[top.Int:top.Int+int32(count)])
+				rt.SAny[top.Any] = parr
+			case core.TYPEARRFLOAT:
+				parr := core.NewArrayFloat()
+				parr.Data = make([]float64, count)
+				top.Float -= int32(count)
+				copy(parr.Data, rt.SFloat[top.Float:top.Float+int32(count)])
+				rt.SAny[top.Any] = parr
 			case core.TYPEMAP:
 				pmap := core.NewMap()
 				pmap.Keys = make([]string, count)
@@ -1017,6 +1229,10 @@ main:
 				}
 			}
 			top.Any++
+			if errID := rt.allocMemory(&top, allocSize(rt.SAny[top.Any-1])); errID != 0 {
+				errHandle(i, errID)
+				continue main
+			}
 
 		case core.RANGE:
 			top.Int -= 2
@@ -1024,38 +1240,61 @@ main:
 			top.Any++
 		case core.ARRAY:
 			count := int(code[i] >> 16)
-			ret := core.NewArray()
-			ret.Data = make([]interface{}, 0, count)
+			i++
+			ret := newValue(rt, int(code[i])).(core.Indexer)
 			for j := 0; j < count; j++ {
 				i++
 				itype := int(code[i] & 0xffff)
 				if int(code[i]>>16) == 1 {
 					top.Any--
-					for k := len(rt.SAny[top.Any].(*core.Array).Data) - 1; k >= 0; k-- {
-						ret.Data = append(ret.Data, rt.SAny[top.Any].(*core.Array).Data[k])
+					switch v := rt.SAny[top.Any].(type) {
+					case *core.ArrayInt:
+						for k := len(v.Data) - 1; k >= 0; k-- {
+							ret.(*core.ArrayInt).Data = append(ret.(*core.ArrayInt).Data, v.Data[k])
+						}
+					case *core.ArrayFloat:
+						for k := len(v.Data) - 1; k >= 0; k-- {
+							ret.(*core.ArrayFloat).Data = append(ret.(*core.ArrayFloat).Data, v.Data[k])
+						}
+					case *core.Array:
+						for k := len(v.Data) - 1; k >= 0; k-- {
+							ret.(*core.Array).Data = append(ret.(*core.Array).Data, v.Data[k])
+						}
 					}
-					//					ret.Data = append(ret.Data, rt.SAny[top.Any].(*core.Array).Data...)
 				} else {
-					var value interface{}
-					switch itype & 0xf {
-					case core.STACKINT:
+					switch v := ret.(type) {
+					case *core.ArrayInt:
 						top.Int--
-						value = rt.SInt[top.Int]
-					case core.STACKSTR:
-						top.Str--
-						value = rt.SStr[top.Str]
-					case core.STACKFLOAT:
+						v.Data = append(v.Data, rt.SInt[top.Int])
+					case *core.ArrayFloat:
 						top.Float--
-						value = rt.SFloat[top.Float]
-					case core.STACKANY:
-						top.Any--
-						value = rt.SAny[top.Any]
+						v.Data = append(v.Data, rt.SFloat[top.Float])
+					case *core.Array:
+						var value interface{}
+						switch itype & 0xf {
+						case core.STACKINT:
+							top.Int--
+							value = rt.SInt[top.Int]
+						case core.STACKSTR:
+							top.Str--
+							value = rt.SStr[top.Str]
+						case core.STACKFLOAT:
+							top.Float--
+							value = rt.SFloat[top.Float]
+						case core.STACKANY:
+							top.Any--
+							value = rt.SAny[top.Any]
+						}
+						v.Data = append(v.Data, value)
 					}
-					ret.Data = append(ret.Data, value)
 				}
 			}
 			rt.SAny[top.Any] = ReverseºArr(ret)
 			top.Any++
+			if errID := rt.allocMemory(&top, allocSize(ret)); errID != 0 {
+				errHandle(i, errID)
+				continue main
+			}
 		case core.LEN:
 			var length int64
 			if code[i]>>16 == core.TYPESTR {
@@ -1076,6 +1315,9 @@ main:
 					break
 				}
 			}
+			if toFinally(k+1, int32(i+1)) {
+				continue
+			}
 			for j := rt.Calls[k].Any; j < top.Any; j++ {
 				rt.SAny[j] = nil
 			}
@@ -1090,6 +1332,9 @@ main:
 					break
 				}
 			}
+			if toFinally(k+1, int32(i+1)) {
+				continue
+			}
 			for j := rt.Calls[k].Any; j < top.Any; j++ {
 				rt.SAny[j] = nil
 			}
@@ -1107,6 +1352,9 @@ main:
 					break
 				}
 			}
+			if toFinally(k+1, int32(i+1)) {
+				continue
+			}
 			for j := rt.Calls[k].Any; j < top.Any; j++ {
 				rt.SAny[j] = nil
 			}
@@ -1127,9 +1375,13 @@ main:
 					break
 				}
 			}
+			if toFinally(k+1, int32(i+1)) {
+				continue
+			}
 			rt.Calls = rt.Calls[:k+1]
-			if len(rt.Calls) == 0 { // return from run function
+			if len(rt.Calls) == 0 || rt.Calls[k].IsCallback { // return from run function or fn callback
 				switch retType {
+				case core.TYPENONE:
 				case core.TYPEINT:
 					result = rt.SInt[top.Int-1]
 				case core.TYPEBOOL:
@@ -1171,6 +1423,243 @@ main:
 				rt.SAny[j] = nil
 			}
 			i = int64(top.Offset)
+		case core.RETS:
+			k := len(rt.Calls) - 1
+			for ; k >= 0; k-- {
+				if rt.Calls[k].IsFunc {
+					break
+				}
+			}
+			if toFinally(k+1, int32(i+1)) {
+				continue
+			}
+			i++
+			counts := uint32(code[i])
+			curTop := top
+			top = rt.Calls[k]
+			rt.Calls = rt.Calls[:k]
+			count := int32(counts >> 24)
+			copy(rt.SInt[top.Int:], rt.SInt[curTop.Int-count:curTop.Int])
+			top.Int += count
+			count = int32(counts>>16) & 0xff
+			copy(rt.SFloat[top.Float:], rt.SFloat[curTop.Float-count:curTop.Float])
+			top.Float += count
+			count = int32(counts>>8) & 0xff
+			copy(rt.SStr[top.Str:], rt.SStr[curTop.Str-count:curTop.Str])
+			top.Str += count
+			count = int32(counts) & 0xff
+			copy(rt.SAny[top.Any:], rt.SAny[curTop.Any-count:curTop.Any])
+			top.Any += count
+			for j := top.Any; j < curTop.Any; j++ {
+				rt.SAny[j] = nil
+			}
+			i = int64(top.Offset)
+		case core.FINALLY:
+			rt.Calls[len(rt.Calls)-1].Flags &^= core.BlFinally
+		case core.ENDFINALLY:
+			k := len(rt.Calls) - 1
+			pending := rt.Calls[k].Pending
+			if pending == 0 {
+				break
+			}
+			rt.Calls[k].Pending = 0
+			if pending > 0 {
+				i = int64(pending - 1)
+				continue
+			}
+			top.Any--
+			err = rt.SAny[top.Any].(error)
+			rt.SAny[top.Any] = nil
+			throw()
+			continue
+		case core.DEFER:
+			top.Any -= 2
+			parr := rt.SAny[top.Any].(*core.Array)
+			parr.Data = append(parr.Data, rt.SAny[top.Any+1])
+			rt.SAny[top.Any] = nil
+			rt.SAny[top.Any+1] = nil
+		case core.DEFERS:
+			var errDefer error
+			top.Any--
+			parr := rt.SAny[top.Any].(*core.Array)
+			rt.SAny[top.Any] = nil
+			// the deferred calls are run in the reverse order
+			for k := len(parr.Data) - 1; k >= 0; k-- {
+				rt.Top = top
+				if _, errFn := rt.callFn(parr.Data[k].(*Fn)); errFn != nil && errDefer == nil {
+					errDefer = errFn
+				}
+			}
+			parr.Data = parr.Data[:0]
+			if errDefer != nil {
+				errHandle(i, errDefer)
+				continue
+			}
+		case core.CATCH:
+			// the error is under the filter on the stack
+			matched := true
+			switch code[i] >> 16 {
+			case core.TYPEINT:
+				top.Int--
+				matched = rt.SInt[top.Int] == int64(rt.SAny[top.Any-1].(*RuntimeError).ID)
+			case core.TYPERANGE:
+				top.Any--
+				r := rt.SAny[top.Any].(*core.Range)
+				rt.SAny[top.Any] = nil
+				id := int64(rt.SAny[top.Any-1].(*RuntimeError).ID)
+				matched = (id >= r.From && id <= r.To) || (id >= r.To && id <= r.From)
+			}
+			if !matched {
+				top.Any--
+				rt.SAny[top.Any] = nil
+				i += int64(int16(code[i+1]))
+				continue
+			}
+			// the error is passed as the parameter of the catch block
+			rt.Calls[len(rt.Calls)-1].Any++
+			rt.ParCount = 1
+			i++
+		case core.THROW:
+			top.Any--
+			err = rt.SAny[top.Any].(error)
+			rt.SAny[top.Any] = nil
+			throw()
+			continue
+		case core.SEND:
+			var value interface{}
+			switch (code[i] >> 16) & 0xf {
+			case core.STACKINT:
+				top.Int--
+				value = rt.SInt[top.Int]
+			case core.STACKFLOAT:
+				top.Float--
+				value = rt.SFloat[top.Float]
+			case core.STACKSTR:
+				top.Str--
+				value = rt.SStr[top.Str]
+			case core.STACKANY:
+				top.Any--
+				CopyVar(rt, &value, rt.SAny[top.Any])
+				rt.SAny[top.Any] = nil
+			}
+			top.Any--
+			ch := rt.SAny[top.Any].(*core.Chan)
+			rt.SAny[top.Any] = nil
+			if errID := rt.chanSend(ch, value); errID != 0 {
+				errHandle(i, errID)
+				continue
+			}
+		case core.RECEIVE, core.RECEIVEOK:
+			top.Any--
+			ch := rt.SAny[top.Any].(*core.Chan)
+			rt.SAny[top.Any] = nil
+			value, ok, errID := rt.chanReceive(ch)
+			if errID != 0 {
+				errHandle(i, errID)
+				continue
+			}
+			isOk := code[i]&0xfff == core.RECEIVEOK
+			if !ok && !isOk && code[i+1] != 0 {
+				i += int64(int16(code[i+1]))
+				continue
+			}
+			if value == nil {
+				value = newValue(rt, int(code[i]>>16))
+			}
+			switch (code[i] >> 16) & 0xf {
+			case core.STACKINT:
+				rt.SInt[top.Int] = value.(int64)
+				top.Int++
+			case core.STACKFLOAT:
+				rt.SFloat[top.Float] = value.(float64)
+				top.Float++
+			case core.STACKSTR:
+				rt.SStr[top.Str] = value.(string)
+				top.Str++
+			case core.STACKANY:
+				rt.SAny[top.Any] = value
+				top.Any++
+			}
+			if isOk {
+				rt.SInt[top.Int] = 0
+				if ok {
+					rt.SInt[top.Int] = 1
+				}
+				top.Int++
+			} else {
+				i++
+			}
+		case core.SELECT:
+			count := int64(code[i] >> 16)
+			sel := make([]selectCase, count)
+			for k := count - 1; k >= 0; k-- {
+				kind := code[i+2+2*k]
+				sel[k].Kind = int(kind & 0xffff)
+				switch sel[k].Kind {
+				case core.SelSend:
+					switch (kind >> 16) & 0xf {
+					case core.STACKINT:
+						top.Int--
+						sel[k].Value = rt.SInt[top.Int]
+					case core.STACKFLOAT:
+						top.Float--
+						sel[k].Value = rt.SFloat[top.Float]
+					case core.STACKSTR:
+						top.Str--
+						sel[k].Value = rt.SStr[top.Str]
+					case core.STACKANY:
+						top.Any--
+						CopyVar(rt, &sel[k].Value, rt.SAny[top.Any])
+						rt.SAny[top.Any] = nil
+					}
+					fallthrough
+				case core.SelReceive:
+					top.Any--
+					sel[k].Chan = rt.SAny[top.Any].(*core.Chan)
+					rt.SAny[top.Any] = nil
+				case core.SelTimeout:
+					top.Int--
+					sel[k].Timeout = rt.SInt[top.Int]
+				}
+			}
+			chosen, value, errID := rt.selectChan(sel)
+			if errID != 0 {
+				errHandle(i, errID)
+				continue
+			}
+			if chosen < 0 {
+				i += int64(int32(code[i+1]))
+				continue
+			}
+			kind := code[i+2+2*int64(chosen)]
+			if varType := int(kind >> 16); kind&0xffff == core.SelReceive && varType != 0 {
+				// the received value is passed as the parameter of the case block
+				if value == nil {
+					value = newValue(rt, varType)
+				}
+				last := len(rt.Calls) - 1
+				switch varType & 0xf {
+				case core.STACKINT:
+					rt.SInt[top.Int] = value.(int64)
+					top.Int++
+					rt.Calls[last].Int++
+				case core.STACKFLOAT:
+					rt.SFloat[top.Float] = value.(float64)
+					top.Float++
+					rt.Calls[last].Float++
+				case core.STACKSTR:
+					rt.SStr[top.Str] = value.(string)
+					top.Str++
+					rt.Calls[last].Str++
+				case core.STACKANY:
+					rt.SAny[top.Any] = value
+					top.Any++
+					rt.Calls[last].Any++
+				}
+				rt.ParCount = 1
+			}
+			i += int64(int32(code[i+3+2*int64(chosen)]))
+			continue
 		case core.END:
 			if len(rt.Calls) == 0 {
 				break main
@@ -1198,14 +1687,16 @@ main:
 			id := int32(code[i])
 			if id == 0 {
 				top.Any--
-				id = rt.SAny[top.Any].(*Fn).Func
-				if id == 0 {
+				fn := rt.SAny[top.Any].(*Fn)
+				if id = fn.Func; id == 0 {
 					errHandle(i, ErrFnEmpty)
 					continue main
 					//return nil, runtimeError(rt, i, ErrFnEmpty)
 				}
+				// captured variables are initialized like optional parameters
+				rt.Optional = fn.Captured
 			}
-			rt.Calls = append(rt.Calls, Call{
+			errID := rt.pushCall(&top, Call{
 				IsFunc:   true,
 				Offset:   int32(i),
 				Int:      top.Int,
@@ -1215,11 +1706,77 @@ main:
 				Optional: rt.Optional,
 			})
 			rt.Optional = nil
-			if uint32(len(rt.Calls)) >= rt.Owner.Settings.Depth {
-				errHandle(i, ErrDepth)
+			if errID != 0 {
+				errHandle(i, errID)
+				continue main
+			}
+			i = int64(rt.Owner.Exec.Funcs[id])
+			continue
+		case core.TAILCALL:
+			rt.ParCount = int32(code[i]) >> 16
+			i += 2
+			counts := uint32(code[i-1])
+			id := int32(code[i])
+			k := len(rt.Calls) - 1
+			for ; k >= 0; k-- {
+				if rt.Calls[k].IsFunc || rt.Calls[k].IsLocal {
+					break
+				}
+			}
+			if k < 0 {
+				// there is not the frame of the function, so it works like CALLBYID
+				errID := rt.pushCall(&top, Call{
+					IsFunc:   true,
+					Offset:   int32(i),
+					Int:      top.Int,
+					Float:    top.Float,
+					Str:      top.Str,
+					Any:      top.Any,
+					Optional: rt.Optional,
+				})
+				rt.Optional = nil
+				if errID != 0 {
+					errHandle(i, errID)
+					continue main
+				}
+				i = int64(rt.Owner.Exec.Funcs[id])
+				continue
+			}
+			frame := &rt.Calls[k]
+			// the frame of the function counts tail calls to stop endless recursion
+			if frame.Cycle++; frame.Cycle > rt.Owner.Settings.Cycle {
+				errHandle(i, ErrCycle)
 				continue main
-				//return nil, runtimeError(rt, i, ErrDepth)
 			}
+			if frame.Tail == nil {
+				frame.Tail = &tailTrace{Offsets: make([]int32, 0, TAILTRACE)}
+			}
+			if len(frame.Tail.Offsets) < TAILTRACE {
+				frame.Tail.Offsets = append(frame.Tail.Offsets, int32(i))
+			} else {
+				frame.Tail.Offsets[TAILTRACE-1] = int32(i)
+				frame.Tail.Elided++
+			}
+			count := int32(counts >> 24)
+			copy(rt.SInt[frame.Int:], rt.SInt[top.Int-count:top.Int])
+			top.Int = frame.Int + count
+			count = int32(counts>>16) & 0xff
+			copy(rt.SFloat[frame.Float:], rt.SFloat[top.Float-count:top.Float])
+			top.Float = frame.Float + count
+			count = int32(counts>>8) & 0xff
+			copy(rt.SStr[frame.Str:], rt.SStr[top.Str-count:top.Str])
+			top.Str = frame.Str + count
+			count = int32(counts) & 0xff
+			copy(rt.SAny[frame.Any:], rt.SAny[top.Any-count:top.Any])
+			for j := frame.Any + count; j < top.Any; j++ {
+				rt.SAny[j] = nil
+			}
+			top.Any = frame.Any + count
+			// INITVARS of the called function shifts the frame back by its parameters
+			frame.Int, frame.Float, frame.Str, frame.Any = top.Int, top.Float, top.Str, top.Any
+			frame.Optional = rt.Optional
+			rt.Optional = nil
+			rt.Calls = rt.Calls[:k+1]
 			i = int64(rt.Owner.Exec.Funcs[id])
 			continue
 		case core.GOBYID:
@@ -1241,6 +1798,7 @@ main:
 		case core.EMBED:
 			var (
 				vCount int
+				size   int64 // the size of the processed data
 			)
 			idEmbed := uint16(code[i] >> 16)
 			embed := EmbedFuncs[idEmbed]
@@ -1259,9 +1817,11 @@ main:
 					case core.STACKSTR:
 						top.Str--
 						pars[count+j] = reflect.ValueOf(rt.SStr[top.Str])
+						size += int64(len(rt.SStr[top.Str]))
 					case core.STACKANY:
 						top.Any--
 						pars[count+j] = reflect.ValueOf(rt.SAny[top.Any])
+						size += allocSize(rt.SAny[top.Any])
 					default:
 						top.Int--
 						pars[count+j] = reflect.ValueOf(rt.SInt[top.Int])
@@ -1277,21 +1837,31 @@ main:
 				case core.STACKSTR:
 					top.Str--
 					pars[i] = reflect.ValueOf(rt.SStr[top.Str])
+					size += int64(len(rt.SStr[top.Str]))
 				case core.STACKANY:
 					top.Any--
 					pars[i] = reflect.ValueOf(rt.SAny[top.Any])
+					size += allocSize(rt.SAny[top.Any])
 				default:
 					top.Int--
 					pars[i] = reflect.ValueOf(rt.SInt[top.Int])
 				}
 			}
 			if embed.Runtime {
+				rt.Top = top
 				pars = append([]reflect.Value{reflect.ValueOf(rt)}, pars...)
 			}
 			result := reflect.ValueOf(embed.Func).Call(pars)
+			if len(result) > 0 && (embed.Return&0xf == core.STACKSTR || embed.Return&0xf == core.STACKANY) {
+				size += allocSize(result[0].Interface())
+			}
+			if metered {
+				rt.GasLeft -= embedWeight(&embed, size)
+			}
 			if len(result) > 0 {
 				last := result[len(result)-1].Interface()
-				if last != nil {
+				// the function can return the error value as the result
+				if last != nil && (len(result) > 1 || embed.Return != core.TYPEERROR) {
 					if _, isError := last.(error); isError {
 						errHandle(i, result[len(result)-1].Interface().(error))
 						continue
@@ -1305,9 +1875,17 @@ main:
 				case core.STACKSTR:
 					rt.SStr[top.Str] = result[0].Interface().(string)
 					top.Str++
+					if errID := rt.allocMemory(&top, int64(len(rt.SStr[top.Str-1]))); errID != 0 {
+						errHandle(i, errID)
+						continue main
+					}
 				case core.STACKANY:
 					rt.SAny[top.Any] = result[0].Interface()
 					top.Any++
+					if errID := rt.allocMemory(&top, allocSize(rt.SAny[top.Any-1])); errID != 0 {
+						errHandle(i, errID)
+						continue main
+					}
 				default:
 					rt.SInt[top.Int] = result[0].Interface().(int64)
 					top.Int++
@@ -1317,18 +1895,16 @@ main:
 			rt.ParCount = int32(code[i]) >> 16
 			i++
 			shift := int32(code[i])
-			rt.Calls = append(rt.Calls, Call{
+			if errID := rt.pushCall(&top, Call{
 				IsLocal: true,
 				Offset:  int32(i),
 				Int:     top.Int,
 				Float:   top.Float,
 				Str:     top.Str,
 				Any:     top.Any,
-			})
-			if uint32(len(rt.Calls)) >= rt.Owner.Settings.Depth {
-				errHandle(i, ErrDepth)
+			}); errID != 0 {
+				errHandle(i, errID)
 				continue main
-				//return nil, runtimeError(rt, i, ErrDepth)
 			}
 			i += int64(shift)
 			continue
@@ -1337,15 +1913,39 @@ main:
 				Type:  core.TYPEINT,
 				Value: int64((int32(code[i]) >> 16) - 1),
 			}
+		case core.INCVAR:
+			base := int(code[i]) >> 16
+			if base >= 0x0f00 {
+				next := base - 0x0f00
+				for base = len(rt.Calls) - 1; base > 0; base-- {
+					if rt.Calls[base].IsFunc {
+						break
+					}
+				}
+				base += next
+			} else {
+				base = len(rt.Calls) - 1 - base
+			}
+			root := rt.Calls[base].Int + int32(code[i+1]&0xffff)
+			if code[i+1]>>16 != 0 {
+				rt.SInt[top.Int] = rt.SInt[root]
+				rt.SInt[root] += int64(code[i+2])
+			} else {
+				rt.SInt[root] += int64(code[i+2])
+				rt.SInt[top.Int] = rt.SInt[root]
+			}
+			top.Int++
+			i += 2
 		}
 		i++
 		/*		if i&0x8 != 0x8 {
 				continue
 			}*/
 		step := SleepStep
-		check := len(rt.Owner.Runtimes) > 1
-		for check || rt.Thread.Status == ThPaused || rt.Thread.Status == ThWait ||
-			rt.Thread.Sleep > 0 {
+		// the status of the single thread can be changed only by itself
+		check := len(rt.Owner.Runtimes) > 1 || rt.Thread.Status == ThPaused ||
+			rt.Thread.Status == ThWait
+		for check || rt.Thread.Sleep > 0 {
 			var x int
 			if rt.ThreadID == 0 {
 				select {
@@ -1358,22 +1958,25 @@ main:
 					}
 				default:
 				}
-			} else {
-				select {
-				case x = <-rt.Thread.Chan:
-					switch x {
-					case ThCmdResume, ThCmdContinue:
-						rt.setStatus(ThWork)
-					case ThCmdClose:
-						rt.setStatus(ThClosed)
-					}
-				default:
-				}
-				if rt.Thread.Status == ThClosed {
-					errHandle(i, ErrThreadClosed)
-					continue main
-					//return nil, runtimeError(rt, i, ErrThreadClosed)
+			}
+			select {
+			case x = <-rt.Thread.Chan:
+				switch x {
+				case ThCmdResume, ThCmdContinue:
+					rt.setStatus(ThWork)
+				case ThCmdClose:
+					rt.setStatus(ThClosed)
 				}
+			default:
+			}
+			status := rt.status()
+			if status == ThClosed && !rt.Thread.Closing {
+				// the closed thread is woken up
+				rt.Thread.Closing = true
+				rt.Thread.Sleep = 0
+				errHandle(i, ErrThreadClosed)
+				continue main
+				//return nil, runtimeError(rt, i, ErrThreadClosed)
 			}
 			if rt.Thread.Sleep > 0 {
 				if step > rt.Thread.Sleep {
@@ -1381,7 +1984,7 @@ main:
 				}
 				time.Sleep(time.Duration(step) * time.Millisecond)
 				rt.Thread.Sleep -= step
-			} else if rt.Thread.Status == ThPaused || rt.Thread.Status == ThWait {
+			} else if status == ThPaused || status == ThWait {
 				if rt.ThreadID == 0 {
 					select {
 					case err = <-rt.Owner.ChError:
@@ -1392,8 +1995,11 @@ main:
 							rt.setStatus(ThWork)
 						}
 					case x = <-rt.Thread.Chan:
-						if x == ThCmdContinue {
+						switch x {
+						case ThCmdContinue:
 							rt.setStatus(ThWork)
+						case ThCmdClose:
+							rt.setStatus(ThClosed)
 						}
 					}
 				} else {
@@ -1408,7 +2014,8 @@ main:
 					}
 				}
 			}
-			check = false
+			status = rt.status()
+			check = status == ThPaused || status == ThWait
 		}
 	}
 	return
@@ -11,8 +11,8 @@ import (
 )
 
 // arrºSet converts set to array of integers
-func arrºSet(set *core.Set) *core.Array {
-	ret := core.NewArray()
+func arrºSet(set *core.Set) *core.ArrayInt {
+	ret := core.NewArrayInt()
 	for i, v := range set.Data {
 		for pos := uint64(0); pos < 64; pos++ {
 			if v&(1<<pos) != 0 {
@@ -92,11 +92,9 @@ func IsSet(set *core.Set, index int64) int64 {
 }
 
 // setºArr converts array of integers to set
-func setºArr(arr *core.Array) (set *core.Set, err error) {
-	var ind int64
+func setºArr(arr *core.ArrayInt) (set *core.Set, err error) {
 	set = core.NewSet()
-	for _, v := range arr.Data {
-		ind = v.(int64)
+	for _, ind := range arr.Data {
 		if err = checkIndex(set, ind); err == nil {
 			set.Set(ind, true)
 		}
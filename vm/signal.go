@@ -0,0 +1,138 @@
+// Copyright 2019 Alexey Krivonogov. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+var signals = map[string]os.Signal{
+	`SIGHUP`:  syscall.SIGHUP,
+	`SIGINT`:  syscall.SIGINT,
+	`SIGQUIT`: syscall.SIGQUIT,
+	`SIGTERM`: syscall.SIGTERM,
+}
+
+// getSignal returns the signal by its name like SIGINT or INT
+func getSignal(name string) (os.Signal, error) {
+	name = strings.ToUpper(name)
+	if !strings.HasPrefix(name, `SIG`) {
+		name = `SIG` + name
+	}
+	if sig, ok := signals[name]; ok {
+		return sig, nil
+	}
+	return nil, fmt.Errorf(ErrorText(ErrSignal), name)
+}
+
+// signalName returns the name of the signal
+func signalName(sig os.Signal) string {
+	for name, item := range signals {
+		if item == sig {
+			return name
+		}
+	}
+	return sig.String()
+}
+
+// handleSignal runs fn of the signal in a new thread. If fn returns true then all threads
+// are closed and the script is stopped.
+func (vm *VM) handleSignal(sig os.Signal, fn *Fn) {
+	thread := vm.newThread(ThQueue)
+	if thread == nil {
+		return
+	}
+	go func() {
+		thread.setStatus(ThWork)
+		var value interface{}
+		CopyVar(thread, &value, fn)
+		copyCaptured(thread, value.(*Fn))
+		result, err := thread.callFn(value.(*Fn))
+		if err == nil && result.(int64) != 0 {
+			vm.stop(fmt.Errorf(ErrorText(ErrStopped), signalName(sig)))
+		}
+		vm.endThread(thread, nil, err, !vm.Settings.NonFatalThreads)
+	}()
+}
+
+// setSignal sets the handler of the signal. nil fn means that the signal is ignored.
+func (rt *Runtime) setSignal(name string, fn *Fn) error {
+	sig, err := getSignal(name)
+	if err != nil {
+		return err
+	}
+	vm := rt.Owner
+	vm.SyncMutex.Lock()
+	defer vm.SyncMutex.Unlock()
+	vm.Signals[sig] = fn
+	if fn == nil {
+		signal.Ignore(sig)
+		return nil
+	}
+	if vm.ChSignal == nil {
+		vm.ChSignal = make(chan os.Signal, 8)
+		ch := vm.ChSignal
+		go func() {
+			for sig := range ch {
+				vm.SyncMutex.Lock()
+				fn := vm.Signals[sig]
+				vm.SyncMutex.Unlock()
+				if fn != nil {
+					vm.handleSignal(sig, fn)
+				}
+			}
+		}()
+	}
+	signal.Notify(vm.ChSignal, sig)
+	return nil
+}
+
+// resetSignals restores the default behavior of the signals which have been changed by the script
+func (vm *VM) resetSignals() {
+	vm.SyncMutex.Lock()
+	defer vm.SyncMutex.Unlock()
+	for sig := range vm.Signals {
+		signal.Reset(sig)
+	}
+	if vm.ChSignal != nil {
+		signal.Stop(vm.ChSignal)
+		close(vm.ChSignal)
+		vm.ChSignal = nil
+	}
+}
+
+// IgnoreSignalºStr ignores the signal
+func IgnoreSignalºStr(rt *Runtime, name string) error {
+	return rt.setSignal(name, nil)
+}
+
+// OnSignalºStrFn sets fn as the handler of the signal. fn is called in a new thread
+// and the script is stopped if it returns true.
+func OnSignalºStrFn(rt *Runtime, name string, fn *Fn) error {
+	if fn == nil || fn.Func == 0 {
+		return fmt.Errorf(ErrorText(ErrFnEmpty))
+	}
+	var value interface{}
+	CopyVar(rt, &value, fn)
+	copyCaptured(rt, value.(*Fn))
+	return rt.setSignal(name, value.(*Fn))
+}
+
+// ResetSignalºStr restores the default behavior of the signal
+func ResetSignalºStr(rt *Runtime, name string) error {
+	sig, err := getSignal(name)
+	if err != nil {
+		return err
+	}
+	rt.Owner.SyncMutex.Lock()
+	delete(rt.Owner.Signals, sig)
+	rt.Owner.SyncMutex.Unlock()
+	signal.Reset(sig)
+	return nil
+}
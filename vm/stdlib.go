@@ -1,6 +1,6 @@
 // Code generated by go generate; DO NOT EDIT.
 // This file was generated by github.com/gentee/gentee/vm/generate/generate.go at
-// 2020/01/20 10:47:39 +05
+// 2026/10/18 22:46:14 UTC
 
 package vm
 
@@ -57,43 +57,75 @@ var EmbedFuncs = []core.Embed{
 		Func: AddHoursºTimeInt, Return: core.TYPESTRUCT, 
 		Params: []uint16{core.TYPESTRUCT,core.TYPEINT}, 
 		Variadic: false, Runtime: true, CanError: false},
-	{Name: "AppendFile", Pars: "str,buf", Ret: "", Code: 12, 
+	{Name: "After", Pars: "int,fn", Ret: "thread", Code: 12, 
+		Func: AfterºIntFn, Return: core.TYPEINT, 
+		Params: []uint16{core.TYPEINT,core.TYPEFUNC}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "All", Pars: "arr*,fn", Ret: "bool", Code: 13, 
+		Func: AllºArr, Return: core.TYPEBOOL, 
+		Params: []uint16{core.TYPESTRUCT,core.TYPEFUNC}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "All", Pars: "map*,fn", Ret: "bool", Code: 14, 
+		Func: AllºMap, Return: core.TYPEBOOL, 
+		Params: []uint16{core.TYPESTRUCT,core.TYPEFUNC}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "Any", Pars: "arr*,fn", Ret: "bool", Code: 15, 
+		Func: AnyºArr, Return: core.TYPEBOOL, 
+		Params: []uint16{core.TYPESTRUCT,core.TYPEFUNC}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "Any", Pars: "map*,fn", Ret: "bool", Code: 16, 
+		Func: AnyºMap, Return: core.TYPEBOOL, 
+		Params: []uint16{core.TYPESTRUCT,core.TYPEFUNC}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "AppendFile", Pars: "str,buf", Ret: "", Code: 17, 
 		Func: AppendFileºStrBuf, Return: core.TYPENONE, 
 		Params: []uint16{core.TYPESTR,core.TYPEBUF}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "AppendFile", Pars: "str,str", Ret: "", Code: 13, 
+	{Name: "AppendFile", Pars: "str,str", Ret: "", Code: 18, 
 		Func: AppendFileºStrStr, Return: core.TYPENONE, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "Arg", Pars: "str", Ret: "str", Code: 14, 
+	{Name: "Arg", Pars: "str", Ret: "str", Code: 19, 
 		Func: ArgºStr, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: true, CanError: false},
-	{Name: "Arg", Pars: "str,int", Ret: "int", Code: 15, 
+	{Name: "Arg", Pars: "str,int", Ret: "int", Code: 20, 
 		Func: ArgºStrInt, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPESTR,core.TYPEINT}, 
 		Variadic: false, Runtime: true, CanError: true},
-	{Name: "Arg", Pars: "str,str", Ret: "str", Code: 16, 
+	{Name: "Arg", Pars: "str,str", Ret: "str", Code: 21, 
 		Func: ArgºStrStr, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: true, CanError: false},
-	{Name: "ArgCount", Pars: "", Ret: "int", Code: 17, 
+	{Name: "ArgCount", Pars: "", Ret: "int", Code: 22, 
 		Func: ArgCount, Return: core.TYPEINT, 
 		Params: nil, 
 		Variadic: false, Runtime: true, CanError: false},
-	{Name: "Args", Pars: "", Ret: "arr.str", Code: 18, 
+	{Name: "ArgOption", Pars: "str,str,str,str,str", Ret: "", Code: 23, 
+		Func: ArgOptionºStrStrStrStrStr, Return: core.TYPENONE, 
+		Params: []uint16{core.TYPESTR,core.TYPESTR,core.TYPESTR,core.TYPESTR,core.TYPESTR}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "ArgRequired", Pars: "str,str,str,str", Ret: "", Code: 24, 
+		Func: ArgRequiredºStrStrStrStr, Return: core.TYPENONE, 
+		Params: []uint16{core.TYPESTR,core.TYPESTR,core.TYPESTR,core.TYPESTR}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "Args", Pars: "", Ret: "arr.str", Code: 25, 
 		Func: Args, Return: core.TYPEARR, 
 		Params: nil, 
 		Variadic: false, Runtime: true, CanError: false},
-	{Name: "Args", Pars: "str", Ret: "arr.str", Code: 19, 
+	{Name: "Args", Pars: "str", Ret: "arr.str", Code: 26, 
 		Func: ArgsºStr, Return: core.TYPEARR, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: true, CanError: false},
-	{Name: "ArgsTail", Pars: "", Ret: "arr.str", Code: 20, 
+	{Name: "ArgsTail", Pars: "", Ret: "arr.str", Code: 27, 
 		Func: ArgsTail, Return: core.TYPEARR, 
 		Params: nil, 
 		Variadic: false, Runtime: true, CanError: false},
-	{Name: "arr", Pars: "set", Ret: "arr.int", Code: 21, 
+	{Name: "ArgUsage", Pars: "", Ret: "str", Code: 28, 
+		Func: ArgUsage, Return: core.TYPESTR, 
+		Params: nil, 
+		Variadic: false, Runtime: true, CanError: false},
+	{Name: "arr", Pars: "set", Ret: "arr.int", Code: 29, 
 		Func: arrºSet, Return: core.TYPEARR, 
 		Params: []uint16{core.TYPESET}, 
 		Variadic: false, Runtime: false, CanError: false},
@@ -105,6 +137,10 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPEBUF, 
 		Params: []uint16{core.TYPEBUF,core.TYPEBUF}, 
 		Variadic: false, Runtime: false, CanError: false},
+	{Name: "Assign", Pars: "chan*,int", Ret: "chan*", Code: 32, 
+		Func: core.AssignAnyFunc(AssignºChanInt), Return: core.TYPESTRUCT, 
+		Params: []uint16{core.TYPESTRUCT,core.TYPEINT}, 
+		Variadic: false, Runtime: false, CanError: true},
 	{Name: "Assign", Pars: "char,char", Ret: "char", Code: core.ASSIGN, 
 		Func: nil, Return: core.TYPECHAR, 
 		Params: []uint16{core.TYPECHAR,core.TYPECHAR}, 
@@ -121,23 +157,23 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEINT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Assign", Pars: "obj,arr*", Ret: "obj", Code: 28, 
+	{Name: "Assign", Pars: "obj,arr*", Ret: "obj", Code: 37, 
 		Func: core.AssignAnyFunc(AssignºObjAny), Return: core.TYPEOBJ, 
 		Params: []uint16{core.TYPEOBJ,core.TYPESTRUCT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Assign", Pars: "obj,bool", Ret: "obj", Code: 29, 
+	{Name: "Assign", Pars: "obj,bool", Ret: "obj", Code: 38, 
 		Func: core.AssignAnyFunc(AssignºObjBool), Return: core.TYPEOBJ, 
 		Params: []uint16{core.TYPEOBJ,core.TYPEBOOL}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Assign", Pars: "obj,float", Ret: "obj", Code: 30, 
+	{Name: "Assign", Pars: "obj,float", Ret: "obj", Code: 39, 
 		Func: core.AssignAnyFunc(AssignºObjAny), Return: core.TYPEOBJ, 
 		Params: []uint16{core.TYPEOBJ,core.TYPEFLOAT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Assign", Pars: "obj,int", Ret: "obj", Code: 31, 
+	{Name: "Assign", Pars: "obj,int", Ret: "obj", Code: 40, 
 		Func: core.AssignAnyFunc(AssignºObjAny), Return: core.TYPEOBJ, 
 		Params: []uint16{core.TYPEOBJ,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Assign", Pars: "obj,map*", Ret: "obj", Code: 32, 
+	{Name: "Assign", Pars: "obj,map*", Ret: "obj", Code: 41, 
 		Func: core.AssignAnyFunc(AssignºObjAny), Return: core.TYPEOBJ, 
 		Params: []uint16{core.TYPEOBJ,core.TYPESTRUCT}, 
 		Variadic: false, Runtime: false, CanError: false},
@@ -145,7 +181,7 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPEOBJ, 
 		Params: []uint16{core.TYPEOBJ,core.TYPEOBJ}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Assign", Pars: "obj,str", Ret: "obj", Code: 34, 
+	{Name: "Assign", Pars: "obj,str", Ret: "obj", Code: 43, 
 		Func: core.AssignAnyFunc(AssignºObjAny), Return: core.TYPEOBJ, 
 		Params: []uint16{core.TYPEOBJ,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
@@ -153,11 +189,11 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPESET, 
 		Params: []uint16{core.TYPESET,core.TYPESET}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Assign", Pars: "str,bool", Ret: "str", Code: 36, 
+	{Name: "Assign", Pars: "str,bool", Ret: "str", Code: 45, 
 		Func: core.AssignStrFunc(AssignºStrBool), Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPEBOOL}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Assign", Pars: "str,int", Ret: "str", Code: 37, 
+	{Name: "Assign", Pars: "str,int", Ret: "str", Code: 46, 
 		Func: core.AssignStrFunc(AssignºStrInt), Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
@@ -169,10 +205,18 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPESTRUCT, 
 		Params: []uint16{core.TYPESTRUCT,core.TYPESTRUCT}, 
 		Variadic: false, Runtime: false, CanError: false},
+	{Name: "AssignºChanChan", Pars: "chan*,chan*", Ret: "chan*", Code: core.ASSIGN, 
+		Func: nil, Return: core.TYPESTRUCT, 
+		Params: []uint16{core.TYPESTRUCT,core.TYPESTRUCT}, 
+		Variadic: false, Runtime: false, CanError: false},
 	{Name: "AssignºFnFn", Pars: "fn,fn", Ret: "fn", Code: core.ASSIGN, 
 		Func: nil, Return: core.TYPEFUNC, 
 		Params: []uint16{core.TYPEFUNC,core.TYPEFUNC}, 
 		Variadic: false, Runtime: false, CanError: false},
+	{Name: "AssignºIfaceIface", Pars: "iface,iface", Ret: "iface", Code: core.ASSIGNPTR, 
+		Func: nil, Return: core.TYPESTRUCT, 
+		Params: []uint16{core.TYPESTRUCT,core.TYPESTRUCT}, 
+		Variadic: false, Runtime: false, CanError: false},
 	{Name: "AssignºMapMap", Pars: "map*,map*", Ret: "map*", Code: core.ASSIGN, 
 		Func: nil, Return: core.TYPESTRUCT, 
 		Params: []uint16{core.TYPESTRUCT,core.TYPESTRUCT}, 
@@ -185,71 +229,75 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEINT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "AssignAddºArr", Pars: "arr*,arr*", Ret: "arr*", Code: 44, 
+	{Name: "AssignAddºArr", Pars: "arr*,arr*", Ret: "arr*", Code: 55, 
 		Func: core.AssignAnyFunc(AssignAddºArr), Return: core.TYPESTRUCT, 
 		Params: []uint16{core.TYPESTRUCT,core.TYPESTRUCT}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "AssignAdd", Pars: "arr.bool,bool", Ret: "arr.bool", Code: 45, 
+	{Name: "AssignAdd", Pars: "arr.bool,bool", Ret: "arr.bool", Code: 56, 
 		Func: core.AssignAnyFunc(AssignAddºArrAny), Return: core.TYPEARR, 
 		Params: []uint16{core.TYPEARR,core.TYPEBOOL}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "AssignAdd", Pars: "arr.int,int", Ret: "arr.int", Code: 46, 
+	{Name: "AssignAdd", Pars: "arr.int,int", Ret: "arr.int", Code: 57, 
 		Func: core.AssignAnyFunc(AssignAddºArrAny), Return: core.TYPEARR, 
 		Params: []uint16{core.TYPEARR,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "AssignAdd", Pars: "arr.obj,obj", Ret: "arr.obj", Code: 47, 
+	{Name: "AssignAdd", Pars: "arr.float,float", Ret: "arr.float", Code: 58, 
+		Func: core.AssignAnyFunc(AssignAddºArrAny), Return: core.TYPEARR, 
+		Params: []uint16{core.TYPEARR,core.TYPEFLOAT}, 
+		Variadic: false, Runtime: false, CanError: false},
+	{Name: "AssignAdd", Pars: "arr.obj,obj", Ret: "arr.obj", Code: 59, 
 		Func: core.AssignAnyFunc(AssignAddºArrAny), Return: core.TYPEARR, 
 		Params: []uint16{core.TYPEARR,core.TYPEOBJ}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "AssignAdd", Pars: "arr.thread,thread", Ret: "arr.thread", Code: 48, 
+	{Name: "AssignAdd", Pars: "arr.thread,thread", Ret: "arr.thread", Code: 60, 
 		Func: core.AssignAnyFunc(AssignAddºArrAny), Return: core.TYPEARR, 
 		Params: []uint16{core.TYPEARR,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "AssignAdd", Pars: "arr.str,str", Ret: "arr.str", Code: 49, 
+	{Name: "AssignAdd", Pars: "arr.str,str", Ret: "arr.str", Code: 61, 
 		Func: core.AssignAnyFunc(AssignAddºArrAny), Return: core.TYPEARR, 
 		Params: []uint16{core.TYPEARR,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "AssignAdd", Pars: "buf,buf", Ret: "buf", Code: 50, 
+	{Name: "AssignAdd", Pars: "buf,buf", Ret: "buf", Code: 62, 
 		Func: core.AssignAnyFunc(AssignAddºBufBuf), Return: core.TYPEBUF, 
 		Params: []uint16{core.TYPEBUF,core.TYPEBUF}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "AssignAdd", Pars: "buf,char", Ret: "buf", Code: 51, 
+	{Name: "AssignAdd", Pars: "buf,char", Ret: "buf", Code: 63, 
 		Func: core.AssignAnyFunc(AssignAddºBufChar), Return: core.TYPEBUF, 
 		Params: []uint16{core.TYPEBUF,core.TYPECHAR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "AssignAdd", Pars: "buf,int", Ret: "buf", Code: 52, 
+	{Name: "AssignAdd", Pars: "buf,int", Ret: "buf", Code: 64, 
 		Func: core.AssignAnyFunc(AssignAddºBufInt), Return: core.TYPEBUF, 
 		Params: []uint16{core.TYPEBUF,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "AssignAdd", Pars: "buf,str", Ret: "buf", Code: 53, 
+	{Name: "AssignAdd", Pars: "buf,str", Ret: "buf", Code: 65, 
 		Func: core.AssignAnyFunc(AssignAddºBufStr), Return: core.TYPEBUF, 
 		Params: []uint16{core.TYPEBUF,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "AssignAdd", Pars: "float,float", Ret: "float", Code: 54, 
+	{Name: "AssignAdd", Pars: "float,float", Ret: "float", Code: 66, 
 		Func: core.AssignFloatFunc(AssignAddºFloatFloat), Return: core.TYPEFLOAT, 
 		Params: []uint16{core.TYPEFLOAT,core.TYPEFLOAT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "AssignAdd", Pars: "int,int", Ret: "int", Code: 55, 
+	{Name: "AssignAdd", Pars: "int,int", Ret: "int", Code: 67, 
 		Func: core.AssignIntFunc(AssignAddºIntInt), Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEINT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "AssignAdd", Pars: "set,set", Ret: "set", Code: 56, 
+	{Name: "AssignAdd", Pars: "set,set", Ret: "set", Code: 68, 
 		Func: core.AssignAnyFunc(AssignAddºSetSet), Return: core.TYPESET, 
 		Params: []uint16{core.TYPESET,core.TYPESET}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "AssignAdd", Pars: "str,char", Ret: "str", Code: 57, 
+	{Name: "AssignAdd", Pars: "str,char", Ret: "str", Code: 69, 
 		Func: core.AssignStrFunc(AssignAddºStrChar), Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPECHAR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "AssignAdd", Pars: "str,str", Ret: "str", Code: 58, 
+	{Name: "AssignAdd", Pars: "str,str", Ret: "str", Code: 70, 
 		Func: core.AssignStrFunc(AssignAddºStrStr), Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "AssignAddºArrArr", Pars: "arr.arr*,arr*", Ret: "arr.arr*", Code: 59, 
+	{Name: "AssignAddºArrArr", Pars: "arr.arr*,arr*", Ret: "arr.arr*", Code: 71, 
 		Func: core.AssignAnyFunc(AssignAddºArrAny), Return: core.TYPEARR, 
 		Params: []uint16{core.TYPEARR,core.TYPESTRUCT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "AssignAddºArrMap", Pars: "arr.map*,map*", Ret: "arr.map*", Code: 60, 
+	{Name: "AssignAddºArrMap", Pars: "arr.map*,map*", Ret: "arr.map*", Code: 72, 
 		Func: core.AssignAnyFunc(AssignAddºArrAny), Return: core.TYPEARR, 
 		Params: []uint16{core.TYPEARR,core.TYPESTRUCT}, 
 		Variadic: false, Runtime: false, CanError: false},
@@ -257,7 +305,7 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPEBUF, 
 		Params: []uint16{core.TYPEBUF,core.TYPEBUF}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "AssignBitAnd", Pars: "int,int", Ret: "int", Code: 62, 
+	{Name: "AssignBitAnd", Pars: "int,int", Ret: "int", Code: 74, 
 		Func: core.AssignIntFunc(AssignBitAndºIntInt), Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEINT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
@@ -281,55 +329,59 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPESTRUCT, 
 		Params: []uint16{core.TYPESTRUCT,core.TYPESTRUCT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "AssignBitOr", Pars: "int,int", Ret: "int", Code: 68, 
+	{Name: "AssignBitOr", Pars: "int,int", Ret: "int", Code: 80, 
 		Func: core.AssignIntFunc(AssignBitOrºIntInt), Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEINT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "AssignBitXor", Pars: "int,int", Ret: "int", Code: 69, 
+	{Name: "AssignBitXor", Pars: "int,int", Ret: "int", Code: 81, 
 		Func: core.AssignIntFunc(AssignBitXorºIntInt), Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEINT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "AssignDiv", Pars: "float,float", Ret: "float", Code: 70, 
+	{Name: "AssignDiv", Pars: "float,float", Ret: "float", Code: 82, 
 		Func: core.AssignFloatFunc(AssignDivºFloatFloat), Return: core.TYPEFLOAT, 
 		Params: []uint16{core.TYPEFLOAT,core.TYPEFLOAT}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "AssignDiv", Pars: "int,int", Ret: "int", Code: 71, 
+	{Name: "AssignDiv", Pars: "int,int", Ret: "int", Code: 83, 
 		Func: core.AssignIntFunc(AssignDivºIntInt), Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEINT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "AssignMod", Pars: "int,int", Ret: "int", Code: 72, 
+	{Name: "AssignMod", Pars: "int,int", Ret: "int", Code: 84, 
 		Func: core.AssignIntFunc(AssignModºIntInt), Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEINT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "AssignLShift", Pars: "int,int", Ret: "int", Code: 73, 
+	{Name: "AssignLShift", Pars: "int,int", Ret: "int", Code: 85, 
 		Func: core.AssignIntFunc(AssignLShiftºIntInt), Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEINT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "AssignMul", Pars: "float,float", Ret: "float", Code: 74, 
+	{Name: "AssignMul", Pars: "float,float", Ret: "float", Code: 86, 
 		Func: core.AssignFloatFunc(AssignMulºFloatFloat), Return: core.TYPEFLOAT, 
 		Params: []uint16{core.TYPEFLOAT,core.TYPEFLOAT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "AssignMul", Pars: "int,int", Ret: "int", Code: 75, 
+	{Name: "AssignMul", Pars: "int,int", Ret: "int", Code: 87, 
 		Func: core.AssignIntFunc(AssignMulºIntInt), Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEINT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "AssignRShift", Pars: "int,int", Ret: "int", Code: 76, 
+	{Name: "AssignRShift", Pars: "int,int", Ret: "int", Code: 88, 
 		Func: core.AssignIntFunc(AssignRShiftºIntInt), Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEINT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "AssignSub", Pars: "float,float", Ret: "float", Code: 77, 
+	{Name: "AssignSub", Pars: "float,float", Ret: "float", Code: 89, 
 		Func: core.AssignFloatFunc(AssignSubºFloatFloat), Return: core.TYPEFLOAT, 
 		Params: []uint16{core.TYPEFLOAT,core.TYPEFLOAT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "AssignSub", Pars: "int,int", Ret: "int", Code: 78, 
+	{Name: "AssignSub", Pars: "int,int", Ret: "int", Code: 90, 
 		Func: core.AssignIntFunc(AssignSubºIntInt), Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEINT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Base64", Pars: "buf", Ret: "str", Code: 79, 
+	{Name: "AtomicAdd", Pars: "str,int", Ret: "int", Code: 91, 
+		Func: AtomicAddºStrInt, Return: core.TYPEINT, 
+		Params: []uint16{core.TYPESTR,core.TYPEINT}, 
+		Variadic: false, Runtime: true, CanError: false},
+	{Name: "Base64", Pars: "buf", Ret: "str", Code: 92, 
 		Func: Base64ºBuf, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPEBUF}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "BaseName", Pars: "str", Ret: "str", Code: 80, 
+	{Name: "BaseName", Pars: "str", Ret: "str", Code: 93, 
 		Func: BaseName, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
@@ -337,7 +389,7 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEINT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "BitAnd", Pars: "set,set", Ret: "set", Code: 82, 
+	{Name: "BitAnd", Pars: "set,set", Ret: "set", Code: 95, 
 		Func: BitAndºSetSet, Return: core.TYPESET, 
 		Params: []uint16{core.TYPESET,core.TYPESET}, 
 		Variadic: false, Runtime: false, CanError: false},
@@ -345,7 +397,7 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "BitNot", Pars: "set", Ret: "set", Code: 84, 
+	{Name: "BitNot", Pars: "set", Ret: "set", Code: 97, 
 		Func: BitNotºSet, Return: core.TYPESET, 
 		Params: []uint16{core.TYPESET}, 
 		Variadic: false, Runtime: false, CanError: false},
@@ -353,7 +405,7 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEINT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "BitOr", Pars: "set,set", Ret: "set", Code: 86, 
+	{Name: "BitOr", Pars: "set,set", Ret: "set", Code: 99, 
 		Func: BitOrºSetSet, Return: core.TYPESET, 
 		Params: []uint16{core.TYPESET,core.TYPESET}, 
 		Variadic: false, Runtime: false, CanError: false},
@@ -361,127 +413,139 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEINT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "bool", Pars: "arr*", Ret: "bool", Code: 88, 
+	{Name: "bool", Pars: "arr*", Ret: "bool", Code: 101, 
 		Func: boolºArr, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPESTRUCT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "bool", Pars: "buf", Ret: "bool", Code: 89, 
+	{Name: "bool", Pars: "buf", Ret: "bool", Code: 102, 
 		Func: boolºBuf, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPEBUF}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "bool", Pars: "float", Ret: "bool", Code: 90, 
+	{Name: "bool", Pars: "float", Ret: "bool", Code: 103, 
 		Func: boolºFloat, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPEFLOAT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "bool", Pars: "int", Ret: "bool", Code: 91, 
+	{Name: "bool", Pars: "int", Ret: "bool", Code: 104, 
 		Func: boolºInt, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "bool", Pars: "obj", Ret: "bool", Code: 92, 
+	{Name: "bool", Pars: "obj", Ret: "bool", Code: 105, 
 		Func: boolºObj, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPEOBJ}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "bool", Pars: "obj,bool", Ret: "bool", Code: 93, 
+	{Name: "bool", Pars: "obj,bool", Ret: "bool", Code: 106, 
 		Func: boolºObjDef, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPEOBJ,core.TYPEBOOL}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "bool", Pars: "map*", Ret: "bool", Code: 94, 
+	{Name: "bool", Pars: "map*", Ret: "bool", Code: 107, 
 		Func: boolºMap, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPESTRUCT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "bool", Pars: "str", Ret: "bool", Code: 95, 
+	{Name: "bool", Pars: "str", Ret: "bool", Code: 108, 
 		Func: boolºStr, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "buf", Pars: "str", Ret: "buf", Code: 96, 
+	{Name: "buf", Pars: "str", Ret: "buf", Code: 109, 
 		Func: bufºStr, Return: core.TYPEBUF, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Ceil", Pars: "float", Ret: "int", Code: 97, 
+	{Name: "Cap", Pars: "chan*", Ret: "int", Code: 110, 
+		Func: CapºChan, Return: core.TYPEINT, 
+		Params: []uint16{core.TYPESTRUCT}, 
+		Variadic: false, Runtime: false, CanError: false},
+	{Name: "Ceil", Pars: "float", Ret: "int", Code: 111, 
 		Func: CeilºFloat, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEFLOAT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "ChDir", Pars: "str", Ret: "", Code: 98, 
+	{Name: "ChDir", Pars: "str", Ret: "", Code: 112, 
 		Func: ChDirºStr, Return: core.TYPENONE, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "Command", Pars: "str", Ret: "", Code: 99, 
+	{Name: "Close", Pars: "chan*", Ret: "", Code: 113, 
+		Func: CloseºChan, Return: core.TYPENONE, 
+		Params: []uint16{core.TYPESTRUCT}, 
+		Variadic: false, Runtime: false, CanError: true},
+	{Name: "Command", Pars: "str", Ret: "", Code: 114, 
 		Func: Command, Return: core.TYPENONE, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "CommandOutput", Pars: "str", Ret: "str", Code: 100, 
+	{Name: "CommandOutput", Pars: "str", Ret: "str", Code: 115, 
 		Func: CommandOutput, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "CopyFile", Pars: "str,str", Ret: "int", Code: 101, 
+	{Name: "CompareAndSwap", Pars: "str,int,int", Ret: "bool", Code: 116, 
+		Func: CompareAndSwapºStrIntInt, Return: core.TYPEBOOL, 
+		Params: []uint16{core.TYPESTR,core.TYPEINT,core.TYPEINT}, 
+		Variadic: false, Runtime: true, CanError: false},
+	{Name: "CopyFile", Pars: "str,str", Ret: "int", Code: 117, 
 		Func: CopyFileºStrStr, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "CreateDir", Pars: "str", Ret: "", Code: 102, 
+	{Name: "CreateDir", Pars: "str", Ret: "", Code: 118, 
 		Func: CreateDirºStr, Return: core.TYPENONE, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "Ctx", Pars: "str", Ret: "str", Code: 103, 
+	{Name: "Ctx", Pars: "str", Ret: "str", Code: 119, 
 		Func: CtxºStr, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: true, CanError: true},
-	{Name: "CtxGet", Pars: "str", Ret: "str", Code: 104, 
+	{Name: "CtxGet", Pars: "str", Ret: "str", Code: 120, 
 		Func: CtxGetºStr, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: true, CanError: true},
-	{Name: "CtxIs", Pars: "str", Ret: "bool", Code: 105, 
+	{Name: "CtxIs", Pars: "str", Ret: "bool", Code: 121, 
 		Func: CtxIsºStr, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: true, CanError: false},
-	{Name: "CtxSet", Pars: "str,bool", Ret: "str", Code: 106, 
+	{Name: "CtxSet", Pars: "str,bool", Ret: "str", Code: 122, 
 		Func: CtxSetºStrBool, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPEBOOL}, 
 		Variadic: false, Runtime: true, CanError: true},
-	{Name: "CtxSet", Pars: "str,float", Ret: "str", Code: 107, 
+	{Name: "CtxSet", Pars: "str,float", Ret: "str", Code: 123, 
 		Func: CtxSetºStrFloat, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPEFLOAT}, 
 		Variadic: false, Runtime: true, CanError: true},
-	{Name: "CtxSet", Pars: "str,int", Ret: "str", Code: 108, 
+	{Name: "CtxSet", Pars: "str,int", Ret: "str", Code: 124, 
 		Func: CtxSetºStrInt, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPEINT}, 
 		Variadic: false, Runtime: true, CanError: true},
-	{Name: "CtxSet", Pars: "str,str", Ret: "str", Code: 109, 
+	{Name: "CtxSet", Pars: "str,str", Ret: "str", Code: 125, 
 		Func: CtxSetºStrStr, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: true, CanError: true},
-	{Name: "CtxValue", Pars: "str", Ret: "str", Code: 110, 
+	{Name: "CtxValue", Pars: "str", Ret: "str", Code: 126, 
 		Func: CtxValueºStr, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: true, CanError: false},
-	{Name: "Date", Pars: "int,int,int", Ret: "time", Code: 111, 
+	{Name: "Date", Pars: "int,int,int", Ret: "time", Code: 127, 
 		Func: DateºInts, Return: core.TYPESTRUCT, 
 		Params: []uint16{core.TYPEINT,core.TYPEINT,core.TYPEINT}, 
 		Variadic: false, Runtime: true, CanError: false},
-	{Name: "DateTime", Pars: "int,int,int,int,int,int", Ret: "time", Code: 112, 
+	{Name: "DateTime", Pars: "int,int,int,int,int,int", Ret: "time", Code: 128, 
 		Func: DateTimeºInts, Return: core.TYPESTRUCT, 
 		Params: []uint16{core.TYPEINT,core.TYPEINT,core.TYPEINT,core.TYPEINT,core.TYPEINT,core.TYPEINT}, 
 		Variadic: false, Runtime: true, CanError: false},
-	{Name: "Days", Pars: "time", Ret: "int", Code: 113, 
+	{Name: "Days", Pars: "time", Ret: "int", Code: 129, 
 		Func: DaysºTime, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPESTRUCT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Del", Pars: "buf,int,int", Ret: "buf", Code: 114, 
+	{Name: "Del", Pars: "buf,int,int", Ret: "buf", Code: 130, 
 		Func: DelºBufIntInt, Return: core.TYPEBUF, 
 		Params: []uint16{core.TYPEBUF,core.TYPEINT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "DelAuto", Pars: "map*,str", Ret: "map*", Code: 115, 
+	{Name: "DelAuto", Pars: "map*,str", Ret: "map*", Code: 131, 
 		Func: DelºMapStr, Return: core.TYPESTRUCT, 
 		Params: []uint16{core.TYPESTRUCT,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Dir", Pars: "str", Ret: "str", Code: 116, 
+	{Name: "Dir", Pars: "str", Ret: "str", Code: 132, 
 		Func: Dir, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Download", Pars: "str,str", Ret: "int", Code: 117, 
+	{Name: "Download", Pars: "str,str", Ret: "int", Code: 133, 
 		Func: Download, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "Ext", Pars: "str", Ret: "str", Code: 118, 
+	{Name: "Ext", Pars: "str", Ret: "str", Code: 134, 
 		Func: Ext, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
@@ -489,11 +553,11 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPEFLOAT, 
 		Params: []uint16{core.TYPEFLOAT,core.TYPEFLOAT}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "Div", Pars: "float,int", Ret: "float", Code: 120, 
+	{Name: "Div", Pars: "float,int", Ret: "float", Code: 136, 
 		Func: DivºFloatInt, Return: core.TYPEFLOAT, 
 		Params: []uint16{core.TYPEFLOAT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "Div", Pars: "int,float", Ret: "float", Code: 121, 
+	{Name: "Div", Pars: "int,float", Ret: "float", Code: 137, 
 		Func: DivºIntFloat, Return: core.TYPEFLOAT, 
 		Params: []uint16{core.TYPEINT,core.TYPEFLOAT}, 
 		Variadic: false, Runtime: false, CanError: true},
@@ -509,7 +573,7 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPEFLOAT,core.TYPEFLOAT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Equal", Pars: "float,int", Ret: "bool", Code: 125, 
+	{Name: "Equal", Pars: "float,int", Ret: "bool", Code: 141, 
 		Func: EqualºFloatInt, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPEFLOAT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
@@ -521,43 +585,63 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Equal", Pars: "time,time", Ret: "bool", Code: 128, 
+	{Name: "Equal", Pars: "time,time", Ret: "bool", Code: 144, 
 		Func: EqualºTimeTime, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPESTRUCT,core.TYPESTRUCT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "ErrID", Pars: "error", Ret: "int", Code: 129, 
+	{Name: "ErrCause", Pars: "error", Ret: "error", Code: 145, 
+		Func: ErrCause, Return: core.TYPEERROR, 
+		Params: []uint16{core.TYPEERROR}, 
+		Variadic: false, Runtime: false, CanError: false},
+	{Name: "ErrID", Pars: "error", Ret: "int", Code: 146, 
 		Func: ErrID, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEERROR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "error", Pars: "int,str", Ret: "", Code: 130, 
+	{Name: "ErrIs", Pars: "error,int", Ret: "bool", Code: 147, 
+		Func: ErrIs, Return: core.TYPEBOOL, 
+		Params: []uint16{core.TYPEERROR,core.TYPEINT}, 
+		Variadic: false, Runtime: false, CanError: false},
+	{Name: "error", Pars: "error,str", Ret: "", Code: 148, 
+		Func: errorºErrStr, Return: core.TYPENONE, 
+		Params: []uint16{core.TYPEERROR,core.TYPESTR}, 
+		Variadic: false, Runtime: false, CanError: true},
+	{Name: "error", Pars: "int,str", Ret: "", Code: 149, 
 		Func: errorºIntStr, Return: core.TYPENONE, 
 		Params: []uint16{core.TYPEINT,core.TYPESTR}, 
 		Variadic: true, Runtime: false, CanError: true},
-	{Name: "ErrText", Pars: "error", Ret: "str", Code: 131, 
+	{Name: "ErrText", Pars: "error", Ret: "str", Code: 150, 
 		Func: ErrText, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPEERROR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "ErrTrace", Pars: "error", Ret: "arr.trace", Code: 132, 
+	{Name: "ErrTrace", Pars: "error", Ret: "arr.trace", Code: 151, 
 		Func: ErrTrace, Return: core.TYPEARR, 
 		Params: []uint16{core.TYPEERROR}, 
 		Variadic: false, Runtime: true, CanError: false},
-	{Name: "ExpStr", Pars: "str,bool", Ret: "str", Code: 133, 
+	{Name: "Every", Pars: "int,fn", Ret: "thread", Code: 152, 
+		Func: EveryºIntFn, Return: core.TYPEINT, 
+		Params: []uint16{core.TYPEINT,core.TYPEFUNC}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "Exit", Pars: "int", Ret: "", Code: 153, 
+		Func: ExitºInt, Return: core.TYPENONE, 
+		Params: []uint16{core.TYPEINT}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "ExpStr", Pars: "str,bool", Ret: "str", Code: 154, 
 		Func: ExpStrºBool, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPEBOOL}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "ExpStr", Pars: "str,char", Ret: "str", Code: 134, 
+	{Name: "ExpStr", Pars: "str,char", Ret: "str", Code: 155, 
 		Func: ExpStrºChar, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPECHAR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "ExpStr", Pars: "str,float", Ret: "str", Code: 135, 
+	{Name: "ExpStr", Pars: "str,float", Ret: "str", Code: 156, 
 		Func: ExpStrºFloat, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPEFLOAT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "ExpStr", Pars: "str,int", Ret: "str", Code: 136, 
+	{Name: "ExpStr", Pars: "str,int", Ret: "str", Code: 157, 
 		Func: ExpStrºInt, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "ExpStr", Pars: "str,obj", Ret: "str", Code: 137, 
+	{Name: "ExpStr", Pars: "str,obj", Ret: "str", Code: 158, 
 		Func: ExpStrºObj, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPEOBJ}, 
 		Variadic: false, Runtime: false, CanError: false},
@@ -565,55 +649,71 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "FileInfo", Pars: "str", Ret: "finfo", Code: 139, 
+	{Name: "Filter", Pars: "arr*,fn", Ret: "arr*", Code: 160, 
+		Func: FilterºArr, Return: core.TYPESTRUCT, 
+		Params: []uint16{core.TYPESTRUCT,core.TYPEFUNC}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "Filter", Pars: "map*,fn", Ret: "map*", Code: 161, 
+		Func: FilterºMap, Return: core.TYPESTRUCT, 
+		Params: []uint16{core.TYPESTRUCT,core.TYPEFUNC}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "FileInfo", Pars: "str", Ret: "finfo", Code: 162, 
 		Func: FileInfoºStr, Return: core.TYPESTRUCT, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: true, CanError: true},
-	{Name: "Find", Pars: "str,str", Ret: "int", Code: 140, 
+	{Name: "Find", Pars: "str,str", Ret: "int", Code: 163, 
 		Func: FindºStrStr, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "FindRegExp", Pars: "str,str", Ret: "arr.arr.str", Code: 141, 
+	{Name: "FindIndex", Pars: "arr*,fn", Ret: "int", Code: 164, 
+		Func: FindIndexºArr, Return: core.TYPEINT, 
+		Params: []uint16{core.TYPESTRUCT,core.TYPEFUNC}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "FindIndex", Pars: "map*,fn", Ret: "str", Code: 165, 
+		Func: FindIndexºMap, Return: core.TYPESTR, 
+		Params: []uint16{core.TYPESTRUCT,core.TYPEFUNC}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "FindRegExp", Pars: "str,str", Ret: "arr.arr.str", Code: 166, 
 		Func: FindRegExpºStrStr, Return: core.TYPEARR, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "float", Pars: "int", Ret: "float", Code: 142, 
+	{Name: "float", Pars: "int", Ret: "float", Code: 167, 
 		Func: floatºInt, Return: core.TYPEFLOAT, 
 		Params: []uint16{core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "float", Pars: "obj", Ret: "float", Code: 143, 
+	{Name: "float", Pars: "obj", Ret: "float", Code: 168, 
 		Func: floatºObj, Return: core.TYPEFLOAT, 
 		Params: []uint16{core.TYPEOBJ}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "float", Pars: "obj,float", Ret: "float", Code: 144, 
+	{Name: "float", Pars: "obj,float", Ret: "float", Code: 169, 
 		Func: floatºObjDef, Return: core.TYPEFLOAT, 
 		Params: []uint16{core.TYPEOBJ,core.TYPEFLOAT}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "float", Pars: "str", Ret: "float", Code: 145, 
+	{Name: "float", Pars: "str", Ret: "float", Code: 170, 
 		Func: floatºStr, Return: core.TYPEFLOAT, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "Floor", Pars: "float", Ret: "int", Code: 146, 
+	{Name: "Floor", Pars: "float", Ret: "int", Code: 171, 
 		Func: FloorºFloat, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEFLOAT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Format", Pars: "str", Ret: "str", Code: 147, 
+	{Name: "Format", Pars: "str", Ret: "str", Code: 172, 
 		Func: FormatºStr, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: true, Runtime: false, CanError: false},
-	{Name: "Format", Pars: "str,time", Ret: "str", Code: 148, 
+	{Name: "Format", Pars: "str,time", Ret: "str", Code: 173, 
 		Func: FormatºTimeStr, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPESTRUCT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "GetCurDir", Pars: "", Ret: "str", Code: 149, 
+	{Name: "GetCurDir", Pars: "", Ret: "str", Code: 174, 
 		Func: GetCurDir, Return: core.TYPESTR, 
 		Params: nil, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "GetEnv", Pars: "str", Ret: "str", Code: 150, 
+	{Name: "GetEnv", Pars: "str", Ret: "str", Code: 175, 
 		Func: GetEnv, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Greater", Pars: "char,char", Ret: "bool", Code: 151, 
+	{Name: "Greater", Pars: "char,char", Ret: "bool", Code: 176, 
 		Func: GreaterºCharChar, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPECHAR,core.TYPECHAR}, 
 		Variadic: false, Runtime: false, CanError: false},
@@ -621,7 +721,7 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPEFLOAT,core.TYPEFLOAT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Greater", Pars: "float,int", Ret: "bool", Code: 153, 
+	{Name: "Greater", Pars: "float,int", Ret: "bool", Code: 178, 
 		Func: GreaterºFloatInt, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPEFLOAT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
@@ -633,47 +733,59 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Greater", Pars: "time,time", Ret: "bool", Code: 156, 
+	{Name: "Greater", Pars: "time,time", Ret: "bool", Code: 181, 
 		Func: GreaterºTimeTime, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPESTRUCT,core.TYPESTRUCT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "HasPrefix", Pars: "str,str", Ret: "bool", Code: 157, 
+	{Name: "GroupBy", Pars: "arr*,fn", Ret: "map*", Code: 182, 
+		Func: GroupByºArr, Return: core.TYPESTRUCT, 
+		Params: []uint16{core.TYPESTRUCT,core.TYPEFUNC}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "GroupBy", Pars: "map*,fn", Ret: "map*", Code: 183, 
+		Func: GroupByºMap, Return: core.TYPESTRUCT, 
+		Params: []uint16{core.TYPESTRUCT,core.TYPEFUNC}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "HasPrefix", Pars: "str,str", Ret: "bool", Code: 184, 
 		Func: HasPrefixºStrStr, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "HasSuffix", Pars: "str,str", Ret: "bool", Code: 158, 
+	{Name: "HasSuffix", Pars: "str,str", Ret: "bool", Code: 185, 
 		Func: HasSuffixºStrStr, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Hex", Pars: "buf", Ret: "str", Code: 159, 
+	{Name: "Hex", Pars: "buf", Ret: "str", Code: 186, 
 		Func: HexºBuf, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPEBUF}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "HTTPGet", Pars: "str", Ret: "buf", Code: 160, 
+	{Name: "HTTPGet", Pars: "str", Ret: "buf", Code: 187, 
 		Func: HTTPGet, Return: core.TYPEBUF, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "HTTPPage", Pars: "str", Ret: "str", Code: 161, 
+	{Name: "HTTPPage", Pars: "str", Ret: "str", Code: 188, 
 		Func: HTTPPage, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "Join", Pars: "arr.str,str", Ret: "str", Code: 162, 
+	{Name: "Join", Pars: "arr.str,str", Ret: "str", Code: 189, 
 		Func: JoinºArrStr, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPEARR,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "JoinPath", Pars: "", Ret: "str", Code: 163, 
+	{Name: "JoinPath", Pars: "", Ret: "str", Code: 190, 
 		Func: JoinPath, Return: core.TYPESTR, 
 		Params: nil, 
 		Variadic: true, Runtime: false, CanError: false},
-	{Name: "Json", Pars: "obj", Ret: "str", Code: 164, 
+	{Name: "Json", Pars: "obj", Ret: "str", Code: 191, 
 		Func: Json, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPEOBJ}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "JsonToObj", Pars: "str", Ret: "obj", Code: 165, 
+	{Name: "JsonToObj", Pars: "str", Ret: "obj", Code: 192, 
 		Func: JsonToObj, Return: core.TYPEOBJ, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "Insert", Pars: "buf,int,buf", Ret: "buf", Code: 166, 
+	{Name: "IgnoreSignal", Pars: "str", Ret: "", Code: 193, 
+		Func: IgnoreSignalºStr, Return: core.TYPENONE, 
+		Params: []uint16{core.TYPESTR}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "Insert", Pars: "buf,int,buf", Ret: "buf", Code: 194, 
 		Func: InsertºBufIntBuf, Return: core.TYPEBUF, 
 		Params: []uint16{core.TYPEBUF,core.TYPEINT,core.TYPEBUF}, 
 		Variadic: false, Runtime: false, CanError: false},
@@ -685,51 +797,51 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPECHAR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "int", Pars: "float", Ret: "int", Code: 169, 
+	{Name: "int", Pars: "float", Ret: "int", Code: 197, 
 		Func: intºFloat, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEFLOAT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "int", Pars: "obj", Ret: "int", Code: 170, 
+	{Name: "int", Pars: "obj", Ret: "int", Code: 198, 
 		Func: intºObj, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEOBJ}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "int", Pars: "obj,int", Ret: "int", Code: 171, 
+	{Name: "int", Pars: "obj,int", Ret: "int", Code: 199, 
 		Func: intºObjDef, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEOBJ,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "int", Pars: "str", Ret: "int", Code: 172, 
+	{Name: "int", Pars: "str", Ret: "int", Code: 200, 
 		Func: intºStr, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "int", Pars: "time", Ret: "int", Code: 173, 
+	{Name: "int", Pars: "time", Ret: "int", Code: 201, 
 		Func: intºTime, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPESTRUCT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "IsArg", Pars: "str", Ret: "bool", Code: 174, 
+	{Name: "IsArg", Pars: "str", Ret: "bool", Code: 202, 
 		Func: IsArgºStr, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: true, CanError: false},
-	{Name: "IsKeyAuto", Pars: "map*,str", Ret: "bool", Code: 175, 
+	{Name: "IsKeyAuto", Pars: "map*,str", Ret: "bool", Code: 203, 
 		Func: IsKeyºMapStr, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPESTRUCT,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "IsNil", Pars: "obj", Ret: "bool", Code: 176, 
+	{Name: "IsNil", Pars: "obj", Ret: "bool", Code: 204, 
 		Func: IsNil, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPEOBJ}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "item", Pars: "obj,int", Ret: "obj", Code: 177, 
+	{Name: "item", Pars: "obj,int", Ret: "obj", Code: 205, 
 		Func: itemºObjInt, Return: core.TYPEOBJ, 
 		Params: []uint16{core.TYPEOBJ,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "item", Pars: "obj,str", Ret: "obj", Code: 178, 
+	{Name: "item", Pars: "obj,str", Ret: "obj", Code: 206, 
 		Func: itemºObjStr, Return: core.TYPEOBJ, 
 		Params: []uint16{core.TYPEOBJ,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "KeyAuto", Pars: "map*,int", Ret: "str", Code: 179, 
+	{Name: "KeyAuto", Pars: "map*,int", Ret: "str", Code: 207, 
 		Func: KeyºMapInt, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTRUCT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "Left", Pars: "str,int", Ret: "str", Code: 180, 
+	{Name: "Left", Pars: "str,int", Ret: "str", Code: 208, 
 		Func: LeftºStrInt, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
@@ -741,6 +853,10 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEBUF}, 
 		Variadic: false, Runtime: false, CanError: false},
+	{Name: "Len", Pars: "chan*", Ret: "int", Code: 211, 
+		Func: LenºChan, Return: core.TYPEINT, 
+		Params: []uint16{core.TYPESTRUCT}, 
+		Variadic: false, Runtime: false, CanError: false},
 	{Name: "LenºMap", Pars: "map*", Ret: "int", Code: core.TYPESTRUCT<<16 | core.LEN, 
 		Func: nil, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPESTRUCT}, 
@@ -757,7 +873,7 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Less", Pars: "char,char", Ret: "bool", Code: 187, 
+	{Name: "Less", Pars: "char,char", Ret: "bool", Code: 216, 
 		Func: LessºCharChar, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPECHAR,core.TYPECHAR}, 
 		Variadic: false, Runtime: false, CanError: false},
@@ -765,7 +881,7 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPEFLOAT,core.TYPEFLOAT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Less", Pars: "float,int", Ret: "bool", Code: 189, 
+	{Name: "Less", Pars: "float,int", Ret: "bool", Code: 218, 
 		Func: LessºFloatInt, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPEFLOAT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
@@ -777,19 +893,23 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Less", Pars: "time,time", Ret: "bool", Code: 192, 
+	{Name: "Less", Pars: "time,time", Ret: "bool", Code: 221, 
 		Func: LessºTimeTime, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPESTRUCT,core.TYPESTRUCT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Lines", Pars: "str", Ret: "arr.str", Code: 193, 
+	{Name: "Lines", Pars: "str", Ret: "arr.str", Code: 222, 
 		Func: LinesºStr, Return: core.TYPEARR, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Lock", Pars: "", Ret: "", Code: 194, 
+	{Name: "Lock", Pars: "", Ret: "", Code: 223, 
 		Func: Lock, Return: core.TYPENONE, 
 		Params: nil, 
 		Variadic: false, Runtime: true, CanError: false},
-	{Name: "Lower", Pars: "str", Ret: "str", Code: 195, 
+	{Name: "Lock", Pars: "str", Ret: "", Code: 224, 
+		Func: LockºStr, Return: core.TYPENONE, 
+		Params: []uint16{core.TYPESTR}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "Lower", Pars: "str", Ret: "str", Code: 225, 
 		Func: LowerºStr, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
@@ -797,39 +917,47 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEINT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "Match", Pars: "str,str", Ret: "bool", Code: 197, 
+	{Name: "Map", Pars: "map*,fn", Ret: "map*", Code: 227, 
+		Func: MapºMap, Return: core.TYPESTRUCT, 
+		Params: []uint16{core.TYPESTRUCT,core.TYPEFUNC}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "MapºArr", Pars: "arr*,fn,arr*", Ret: "arr*", Code: 228, 
+		Func: MapºArr, Return: core.TYPESTRUCT, 
+		Params: []uint16{core.TYPESTRUCT,core.TYPEFUNC,core.TYPESTRUCT}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "Match", Pars: "str,str", Ret: "bool", Code: 229, 
 		Func: MatchºStrStr, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "MatchPath", Pars: "str,str", Ret: "bool", Code: 198, 
+	{Name: "MatchPath", Pars: "str,str", Ret: "bool", Code: 230, 
 		Func: MatchPath, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "Max", Pars: "float,float", Ret: "float", Code: 199, 
+	{Name: "Max", Pars: "float,float", Ret: "float", Code: 231, 
 		Func: MaxºFloatFloat, Return: core.TYPEFLOAT, 
 		Params: []uint16{core.TYPEFLOAT,core.TYPEFLOAT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Max", Pars: "int,int", Ret: "int", Code: 200, 
+	{Name: "Max", Pars: "int,int", Ret: "int", Code: 232, 
 		Func: MaxºIntInt, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEINT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Md5", Pars: "buf", Ret: "buf", Code: 201, 
+	{Name: "Md5", Pars: "buf", Ret: "buf", Code: 233, 
 		Func: Md5ºBuf, Return: core.TYPEBUF, 
 		Params: []uint16{core.TYPEBUF}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Md5", Pars: "str", Ret: "buf", Code: 202, 
+	{Name: "Md5", Pars: "str", Ret: "buf", Code: 234, 
 		Func: Md5ºStr, Return: core.TYPEBUF, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Md5File", Pars: "str", Ret: "str", Code: 203, 
+	{Name: "Md5File", Pars: "str", Ret: "str", Code: 235, 
 		Func: Md5FileºStr, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "Min", Pars: "float,float", Ret: "float", Code: 204, 
+	{Name: "Min", Pars: "float,float", Ret: "float", Code: 236, 
 		Func: MinºFloatFloat, Return: core.TYPEFLOAT, 
 		Params: []uint16{core.TYPEFLOAT,core.TYPEFLOAT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Min", Pars: "int,int", Ret: "int", Code: 205, 
+	{Name: "Min", Pars: "int,int", Ret: "int", Code: 237, 
 		Func: MinºIntInt, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEINT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
@@ -841,11 +969,11 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPEFLOAT, 
 		Params: []uint16{core.TYPEFLOAT,core.TYPEFLOAT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Mul", Pars: "float,int", Ret: "float", Code: 208, 
+	{Name: "Mul", Pars: "float,int", Ret: "float", Code: 240, 
 		Func: MulºFloatInt, Return: core.TYPEFLOAT, 
 		Params: []uint16{core.TYPEFLOAT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Mul", Pars: "int,float", Ret: "float", Code: 209, 
+	{Name: "Mul", Pars: "int,float", Ret: "float", Code: 241, 
 		Func: MulºIntFloat, Return: core.TYPEFLOAT, 
 		Params: []uint16{core.TYPEINT,core.TYPEFLOAT}, 
 		Variadic: false, Runtime: false, CanError: false},
@@ -853,6 +981,10 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEINT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
+	{Name: "NextCron", Pars: "str,time", Ret: "time", Code: 243, 
+		Func: NextCronºStrTime, Return: core.TYPESTRUCT, 
+		Params: []uint16{core.TYPESTR,core.TYPESTRUCT}, 
+		Variadic: false, Runtime: true, CanError: true},
 	{Name: "NewKeyValue", Pars: "int,int", Ret: "keyval", Code: core.NOP, 
 		Func: nil, Return: core.TYPESTRUCT, 
 		Params: []uint16{core.TYPEINT,core.TYPEINT}, 
@@ -865,123 +997,175 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPEBOOL}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Now", Pars: "", Ret: "time", Code: 214, 
+	{Name: "Now", Pars: "", Ret: "time", Code: 247, 
 		Func: Now, Return: core.TYPESTRUCT, 
 		Params: nil, 
 		Variadic: false, Runtime: true, CanError: false},
-	{Name: "obj", Pars: "arr*", Ret: "obj", Code: 215, 
+	{Name: "obj", Pars: "arr*", Ret: "obj", Code: 248, 
 		Func: objºArrMap, Return: core.TYPEOBJ, 
 		Params: []uint16{core.TYPESTRUCT}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "obj", Pars: "bool", Ret: "obj", Code: 216, 
+	{Name: "obj", Pars: "bool", Ret: "obj", Code: 249, 
 		Func: objºBool, Return: core.TYPEOBJ, 
 		Params: []uint16{core.TYPEBOOL}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "obj", Pars: "float", Ret: "obj", Code: 217, 
+	{Name: "obj", Pars: "float", Ret: "obj", Code: 250, 
 		Func: objºAny, Return: core.TYPEOBJ, 
 		Params: []uint16{core.TYPEFLOAT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "obj", Pars: "int", Ret: "obj", Code: 218, 
+	{Name: "obj", Pars: "int", Ret: "obj", Code: 251, 
 		Func: objºAny, Return: core.TYPEOBJ, 
 		Params: []uint16{core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "obj", Pars: "map*", Ret: "obj", Code: 219, 
+	{Name: "obj", Pars: "map*", Ret: "obj", Code: 252, 
 		Func: objºArrMap, Return: core.TYPEOBJ, 
 		Params: []uint16{core.TYPESTRUCT}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "obj", Pars: "str", Ret: "obj", Code: 220, 
+	{Name: "obj", Pars: "str", Ret: "obj", Code: 253, 
 		Func: objºAny, Return: core.TYPEOBJ, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Open", Pars: "str", Ret: "", Code: 221, 
+	{Name: "Open", Pars: "str", Ret: "", Code: 254, 
 		Func: OpenºStr, Return: core.TYPENONE, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "OpenWith", Pars: "str,str", Ret: "", Code: 222, 
+	{Name: "OpenWith", Pars: "str,str", Ret: "", Code: 255, 
 		Func: OpenWithºStr, Return: core.TYPENONE, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "ParseTime", Pars: "str,str", Ret: "time", Code: 223, 
+	{Name: "OnSignal", Pars: "str,fn", Ret: "", Code: 256, 
+		Func: OnSignalºStrFn, Return: core.TYPENONE, 
+		Params: []uint16{core.TYPESTR,core.TYPEFUNC}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "ParallelFor", Pars: "arr*,int,fn", Ret: "", Code: 257, 
+		Func: ParallelForºArr, Return: core.TYPENONE, 
+		Params: []uint16{core.TYPESTRUCT,core.TYPEINT,core.TYPEFUNC}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "ParallelFor", Pars: "range,int,fn", Ret: "", Code: 258, 
+		Func: ParallelForºRange, Return: core.TYPENONE, 
+		Params: []uint16{core.TYPERANGE,core.TYPEINT,core.TYPEFUNC}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "ParallelMapºArr", Pars: "arr*,int,fn,arr*", Ret: "arr*", Code: 259, 
+		Func: ParallelMapºArr, Return: core.TYPESTRUCT, 
+		Params: []uint16{core.TYPESTRUCT,core.TYPEINT,core.TYPEFUNC,core.TYPESTRUCT}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "ParseArgs", Pars: "", Ret: "obj", Code: 260, 
+		Func: ParseArgs, Return: core.TYPEOBJ, 
+		Params: nil, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "ParseTime", Pars: "str,str", Ret: "time", Code: 261, 
 		Func: ParseTimeºStrStr, Return: core.TYPESTRUCT, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: true, CanError: true},
-	{Name: "Print", Pars: "", Ret: "int", Code: 224, 
+	{Name: "Print", Pars: "", Ret: "int", Code: 262, 
 		Func: Print, Return: core.TYPEINT, 
 		Params: nil, 
 		Variadic: true, Runtime: false, CanError: true},
-	{Name: "Println", Pars: "", Ret: "int", Code: 225, 
+	{Name: "Println", Pars: "", Ret: "int", Code: 263, 
 		Func: Println, Return: core.TYPEINT, 
 		Params: nil, 
 		Variadic: true, Runtime: false, CanError: true},
-	{Name: "PrintShift", Pars: "str", Ret: "int", Code: 226, 
+	{Name: "PrintShift", Pars: "str", Ret: "int", Code: 264, 
 		Func: PrintShiftºStr, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "ReadDir", Pars: "str", Ret: "arr.finfo", Code: 227, 
+	{Name: "RLock", Pars: "str", Ret: "", Code: 265, 
+		Func: RLockºStr, Return: core.TYPENONE, 
+		Params: []uint16{core.TYPESTR}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "ReadDir", Pars: "str", Ret: "arr.finfo", Code: 266, 
 		Func: ReadDirºStr, Return: core.TYPEARR, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: true, CanError: true},
-	{Name: "ReadFile", Pars: "str", Ret: "str", Code: 228, 
+	{Name: "ReadFile", Pars: "str", Ret: "str", Code: 267, 
 		Func: ReadFileºStr, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "ReadFile", Pars: "str,buf", Ret: "buf", Code: 229, 
+	{Name: "ReadFile", Pars: "str,buf", Ret: "buf", Code: 268, 
 		Func: ReadFileºStrBuf, Return: core.TYPEBUF, 
 		Params: []uint16{core.TYPESTR,core.TYPEBUF}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "ReadFile", Pars: "str,int,int", Ret: "buf", Code: 230, 
+	{Name: "ReadFile", Pars: "str,int,int", Ret: "buf", Code: 269, 
 		Func: ReadFileºStrIntInt, Return: core.TYPEBUF, 
 		Params: []uint16{core.TYPESTR,core.TYPEINT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "ReadString", Pars: "str", Ret: "str", Code: 231, 
+	{Name: "ReadString", Pars: "str", Ret: "str", Code: 270, 
 		Func: ReadString, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: true, CanError: true},
-	{Name: "RegExp", Pars: "str,str", Ret: "str", Code: 232, 
+	{Name: "Reduce", Pars: "arr*,fn,float", Ret: "float", Code: 271, 
+		Func: ReduceºArrFloat, Return: core.TYPEFLOAT, 
+		Params: []uint16{core.TYPESTRUCT,core.TYPEFUNC,core.TYPEFLOAT}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "Reduce", Pars: "arr*,fn,int", Ret: "int", Code: 272, 
+		Func: ReduceºArrInt, Return: core.TYPEINT, 
+		Params: []uint16{core.TYPESTRUCT,core.TYPEFUNC,core.TYPEINT}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "Reduce", Pars: "arr*,fn,str", Ret: "str", Code: 273, 
+		Func: ReduceºArrStr, Return: core.TYPESTR, 
+		Params: []uint16{core.TYPESTRUCT,core.TYPEFUNC,core.TYPESTR}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "Reduce", Pars: "map*,fn,float", Ret: "float", Code: 274, 
+		Func: ReduceºMapFloat, Return: core.TYPEFLOAT, 
+		Params: []uint16{core.TYPESTRUCT,core.TYPEFUNC,core.TYPEFLOAT}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "Reduce", Pars: "map*,fn,int", Ret: "int", Code: 275, 
+		Func: ReduceºMapInt, Return: core.TYPEINT, 
+		Params: []uint16{core.TYPESTRUCT,core.TYPEFUNC,core.TYPEINT}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "Reduce", Pars: "map*,fn,str", Ret: "str", Code: 276, 
+		Func: ReduceºMapStr, Return: core.TYPESTR, 
+		Params: []uint16{core.TYPESTRUCT,core.TYPEFUNC,core.TYPESTR}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "RegExp", Pars: "str,str", Ret: "str", Code: 277, 
 		Func: RegExpºStrStr, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "Remove", Pars: "str", Ret: "", Code: 233, 
+	{Name: "Remove", Pars: "str", Ret: "", Code: 278, 
 		Func: RemoveºStr, Return: core.TYPENONE, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "RemoveDir", Pars: "str", Ret: "", Code: 234, 
+	{Name: "RemoveDir", Pars: "str", Ret: "", Code: 279, 
 		Func: RemoveDirºStr, Return: core.TYPENONE, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "Rename", Pars: "str,str", Ret: "", Code: 235, 
+	{Name: "Rename", Pars: "str,str", Ret: "", Code: 280, 
 		Func: RenameºStrStr, Return: core.TYPENONE, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "Repeat", Pars: "str,int", Ret: "str", Code: 236, 
+	{Name: "Repeat", Pars: "str,int", Ret: "str", Code: 281, 
 		Func: RepeatºStrInt, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Replace", Pars: "str,str,str", Ret: "str", Code: 237, 
+	{Name: "Replace", Pars: "str,str,str", Ret: "str", Code: 282, 
 		Func: ReplaceºStrStrStr, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "ReplaceRegExp", Pars: "str,str,str", Ret: "str", Code: 238, 
+	{Name: "ReplaceRegExp", Pars: "str,str,str", Ret: "str", Code: 283, 
 		Func: ReplaceRegExpºStrStr, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "ReverseAuto", Pars: "arr*", Ret: "arr*", Code: 239, 
+	{Name: "ReverseAuto", Pars: "arr*", Ret: "arr*", Code: 284, 
 		Func: ReverseºArr, Return: core.TYPESTRUCT, 
 		Params: []uint16{core.TYPESTRUCT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "resume", Pars: "thread", Ret: "", Code: 240, 
+	{Name: "ResetSignal", Pars: "str", Ret: "", Code: 285, 
+		Func: ResetSignalºStr, Return: core.TYPENONE, 
+		Params: []uint16{core.TYPESTR}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "resume", Pars: "thread", Ret: "", Code: 286, 
 		Func: resumeºThread, Return: core.TYPENONE, 
 		Params: []uint16{core.TYPEINT}, 
 		Variadic: false, Runtime: true, CanError: true},
-	{Name: "Right", Pars: "str,int", Ret: "str", Code: 241, 
+	{Name: "Right", Pars: "str,int", Ret: "str", Code: 287, 
 		Func: RightºStrInt, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Round", Pars: "float", Ret: "int", Code: 242, 
+	{Name: "Round", Pars: "float", Ret: "int", Code: 288, 
 		Func: RoundºFloat, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEFLOAT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Round", Pars: "float,int", Ret: "float", Code: 243, 
+	{Name: "Round", Pars: "float,int", Ret: "float", Code: 289, 
 		Func: RoundºFloatInt, Return: core.TYPEFLOAT, 
 		Params: []uint16{core.TYPEFLOAT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
@@ -989,47 +1173,59 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEINT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "set", Pars: "arr.int", Ret: "set", Code: 245, 
+	{Name: "RUnlock", Pars: "str", Ret: "", Code: 291, 
+		Func: RUnlockºStr, Return: core.TYPENONE, 
+		Params: []uint16{core.TYPESTR}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "RunTasks", Pars: "bool", Ret: "", Code: 292, 
+		Func: RunTasksºBool, Return: core.TYPENONE, 
+		Params: []uint16{core.TYPEBOOL}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "Schedule", Pars: "str,fn", Ret: "thread", Code: 293, 
+		Func: ScheduleºStrFn, Return: core.TYPEINT, 
+		Params: []uint16{core.TYPESTR,core.TYPEFUNC}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "set", Pars: "arr.int", Ret: "set", Code: 294, 
 		Func: setºArr, Return: core.TYPESET, 
 		Params: []uint16{core.TYPEARR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "Set", Pars: "set,int", Ret: "set", Code: 246, 
+	{Name: "Set", Pars: "set,int", Ret: "set", Code: 295, 
 		Func: SetºSet, Return: core.TYPESET, 
 		Params: []uint16{core.TYPESET,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "set", Pars: "str", Ret: "set", Code: 247, 
+	{Name: "set", Pars: "str", Ret: "set", Code: 296, 
 		Func: setºStr, Return: core.TYPESET, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "SetEnv", Pars: "str,str", Ret: "str", Code: 248, 
+	{Name: "SetEnv", Pars: "str,str", Ret: "str", Code: 297, 
 		Func: SetEnv, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "SetEnv", Pars: "str,int", Ret: "str", Code: 249, 
+	{Name: "SetEnv", Pars: "str,int", Ret: "str", Code: 298, 
 		Func: SetEnv, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "SetEnv", Pars: "str,bool", Ret: "str", Code: 250, 
+	{Name: "SetEnv", Pars: "str,bool", Ret: "str", Code: 299, 
 		Func: SetEnvBool, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPEBOOL}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "SetFileTime", Pars: "str,time", Ret: "", Code: 251, 
+	{Name: "SetFileTime", Pars: "str,time", Ret: "", Code: 300, 
 		Func: SetFileTimeºStrTime, Return: core.TYPENONE, 
 		Params: []uint16{core.TYPESTR,core.TYPESTRUCT}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "Sha256", Pars: "buf", Ret: "buf", Code: 252, 
+	{Name: "Sha256", Pars: "buf", Ret: "buf", Code: 301, 
 		Func: Sha256ºBuf, Return: core.TYPEBUF, 
 		Params: []uint16{core.TYPEBUF}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Sha256", Pars: "str", Ret: "buf", Code: 253, 
+	{Name: "Sha256", Pars: "str", Ret: "buf", Code: 302, 
 		Func: Sha256ºStr, Return: core.TYPEBUF, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Sha256File", Pars: "str", Ret: "str", Code: 254, 
+	{Name: "Sha256File", Pars: "str", Ret: "str", Code: 303, 
 		Func: Sha256FileºStr, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "Shift", Pars: "str", Ret: "str", Code: 255, 
+	{Name: "Shift", Pars: "str", Ret: "str", Code: 304, 
 		Func: ShiftºStr, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
@@ -1041,51 +1237,71 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "sleep", Pars: "int", Ret: "", Code: 258, 
+	{Name: "sleep", Pars: "int", Ret: "", Code: 307, 
 		Func: sleepºInt, Return: core.TYPENONE, 
 		Params: []uint16{core.TYPEINT}, 
 		Variadic: false, Runtime: true, CanError: false},
-	{Name: "SliceAuto", Pars: "arr*,int,int", Ret: "arr*", Code: 259, 
+	{Name: "SliceAuto", Pars: "arr*,int,int", Ret: "arr*", Code: 308, 
 		Func: SliceºArr, Return: core.TYPESTRUCT, 
 		Params: []uint16{core.TYPESTRUCT,core.TYPEINT,core.TYPEINT}, 
 		Variadic: false, Runtime: true, CanError: true},
-	{Name: "Sort", Pars: "arr.str", Ret: "arr.str", Code: 260, 
+	{Name: "Sort", Pars: "arr.str", Ret: "arr.str", Code: 309, 
 		Func: SortºArr, Return: core.TYPEARR, 
 		Params: []uint16{core.TYPEARR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Split", Pars: "str,str", Ret: "arr.str", Code: 261, 
+	{Name: "Sort", Pars: "arr.float", Ret: "arr.float", Code: 310, 
+		Func: SortºArrFloat, Return: core.TYPEARR, 
+		Params: []uint16{core.TYPEARR}, 
+		Variadic: false, Runtime: false, CanError: false},
+	{Name: "Sort", Pars: "arr.int", Ret: "arr.int", Code: 311, 
+		Func: SortºArrInt, Return: core.TYPEARR, 
+		Params: []uint16{core.TYPEARR}, 
+		Variadic: false, Runtime: false, CanError: false},
+	{Name: "Sort", Pars: "arr*,fn", Ret: "arr*", Code: 312, 
+		Func: SortºArrFn, Return: core.TYPESTRUCT, 
+		Params: []uint16{core.TYPESTRUCT,core.TYPEFUNC}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "SortBy", Pars: "arr*,fn", Ret: "arr*", Code: 313, 
+		Func: SortByºArr, Return: core.TYPESTRUCT, 
+		Params: []uint16{core.TYPESTRUCT,core.TYPEFUNC}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "SortStable", Pars: "arr*,fn", Ret: "arr*", Code: 314, 
+		Func: SortStableºArr, Return: core.TYPESTRUCT, 
+		Params: []uint16{core.TYPESTRUCT,core.TYPEFUNC}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "Split", Pars: "str,str", Ret: "arr.str", Code: 315, 
 		Func: SplitºStrStr, Return: core.TYPEARR, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "str", Pars: "bool", Ret: "str", Code: 262, 
+	{Name: "str", Pars: "bool", Ret: "str", Code: 316, 
 		Func: strºBool, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPEBOOL}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "str", Pars: "buf", Ret: "str", Code: 263, 
+	{Name: "str", Pars: "buf", Ret: "str", Code: 317, 
 		Func: strºBuf, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPEBUF}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "str", Pars: "char", Ret: "str", Code: 264, 
+	{Name: "str", Pars: "char", Ret: "str", Code: 318, 
 		Func: strºChar, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPECHAR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "str", Pars: "float", Ret: "str", Code: 265, 
+	{Name: "str", Pars: "float", Ret: "str", Code: 319, 
 		Func: strºFloat, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPEFLOAT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "str", Pars: "int", Ret: "str", Code: 266, 
+	{Name: "str", Pars: "int", Ret: "str", Code: 320, 
 		Func: strºInt, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "str", Pars: "obj", Ret: "str", Code: 267, 
+	{Name: "str", Pars: "obj", Ret: "str", Code: 321, 
 		Func: strºObj, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPEOBJ}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "str", Pars: "obj,str", Ret: "str", Code: 268, 
+	{Name: "str", Pars: "obj,str", Ret: "str", Code: 322, 
 		Func: strºObjDef, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPEOBJ,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "str", Pars: "set", Ret: "str", Code: 269, 
+	{Name: "str", Pars: "set", Ret: "str", Code: 323, 
 		Func: strºSet, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESET}, 
 		Variadic: false, Runtime: false, CanError: false},
@@ -1093,11 +1309,11 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPEFLOAT, 
 		Params: []uint16{core.TYPEFLOAT,core.TYPEFLOAT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Sub", Pars: "float,int", Ret: "float", Code: 271, 
+	{Name: "Sub", Pars: "float,int", Ret: "float", Code: 325, 
 		Func: SubºFloatInt, Return: core.TYPEFLOAT, 
 		Params: []uint16{core.TYPEFLOAT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Sub", Pars: "int,float", Ret: "float", Code: 272, 
+	{Name: "Sub", Pars: "int,float", Ret: "float", Code: 326, 
 		Func: SubºIntFloat, Return: core.TYPEFLOAT, 
 		Params: []uint16{core.TYPEINT,core.TYPEFLOAT}, 
 		Variadic: false, Runtime: false, CanError: false},
@@ -1105,121 +1321,145 @@ var EmbedFuncs = []core.Embed{
 		Func: nil, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPEINT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Substr", Pars: "str,int,int", Ret: "str", Code: 274, 
+	{Name: "Substr", Pars: "str,int,int", Ret: "str", Code: 328, 
 		Func: SubstrºStrIntInt, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPEINT,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "suspend", Pars: "thread", Ret: "", Code: 275, 
+	{Name: "suspend", Pars: "thread", Ret: "", Code: 329, 
 		Func: suspendºThread, Return: core.TYPENONE, 
 		Params: []uint16{core.TYPEINT}, 
 		Variadic: false, Runtime: true, CanError: true},
-	{Name: "sysBufNil", Pars: "", Ret: "buf", Code: 276, 
+	{Name: "sysBufNil", Pars: "", Ret: "buf", Code: 330, 
 		Func: sysBufNil, Return: core.TYPEBUF, 
 		Params: nil, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "sysRun", Pars: "str,bool,buf,buf,buf,arr.str", Ret: "", Code: 277, 
+	{Name: "sysRun", Pars: "str,bool,buf,buf,buf,arr.str", Ret: "", Code: 331, 
 		Func: sysRun, Return: core.TYPENONE, 
 		Params: []uint16{core.TYPESTR,core.TYPEBOOL,core.TYPEBUF,core.TYPEBUF,core.TYPEBUF,core.TYPEARR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "TempDir", Pars: "", Ret: "str", Code: 278, 
+	{Name: "Task", Pars: "str,fn,str", Ret: "", Code: 332, 
+		Func: TaskºStrFnStr, Return: core.TYPENONE, 
+		Params: []uint16{core.TYPESTR,core.TYPEFUNC,core.TYPESTR}, 
+		Variadic: true, Runtime: true, CanError: true},
+	{Name: "TempDir", Pars: "", Ret: "str", Code: 333, 
 		Func: TempDir, Return: core.TYPESTR, 
 		Params: nil, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "TempDir", Pars: "str,str", Ret: "str", Code: 279, 
+	{Name: "TempDir", Pars: "str,str", Ret: "str", Code: 334, 
 		Func: TempDirºStrStr, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "terminate", Pars: "thread", Ret: "", Code: 280, 
+	{Name: "terminate", Pars: "thread", Ret: "", Code: 335, 
 		Func: terminateºThread, Return: core.TYPENONE, 
 		Params: []uint16{core.TYPEINT}, 
 		Variadic: false, Runtime: true, CanError: true},
-	{Name: "time", Pars: "int", Ret: "time", Code: 281, 
+	{Name: "time", Pars: "int", Ret: "time", Code: 336, 
 		Func: timeºInt, Return: core.TYPESTRUCT, 
 		Params: []uint16{core.TYPEINT}, 
 		Variadic: false, Runtime: true, CanError: false},
-	{Name: "Toggle", Pars: "set,int", Ret: "bool", Code: 282, 
+	{Name: "Toggle", Pars: "set,int", Ret: "bool", Code: 337, 
 		Func: ToggleºSetInt, Return: core.TYPEBOOL, 
 		Params: []uint16{core.TYPESET,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Trace", Pars: "", Ret: "arr.trace", Code: 283, 
+	{Name: "ThreadStatus", Pars: "thread", Ret: "int", Code: 338, 
+		Func: ThreadStatusºThread, Return: core.TYPEINT, 
+		Params: []uint16{core.TYPEINT}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "Threads", Pars: "", Ret: "arr.thread", Code: 339, 
+		Func: Threads, Return: core.TYPEARR, 
+		Params: nil, 
+		Variadic: false, Runtime: true, CanError: false},
+	{Name: "Trace", Pars: "", Ret: "arr.trace", Code: 340, 
 		Func: Trace, Return: core.TYPEARR, 
 		Params: nil, 
 		Variadic: false, Runtime: true, CanError: false},
-	{Name: "Trim", Pars: "str,str", Ret: "str", Code: 284, 
+	{Name: "Trim", Pars: "str,str", Ret: "str", Code: 341, 
 		Func: TrimºStr, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "TrimLeft", Pars: "str,str", Ret: "str", Code: 285, 
+	{Name: "TrimLeft", Pars: "str,str", Ret: "str", Code: 342, 
 		Func: TrimLeftºStr, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "TrimRight", Pars: "str,str", Ret: "str", Code: 286, 
+	{Name: "TrimRight", Pars: "str,str", Ret: "str", Code: 343, 
 		Func: TrimRightºStr, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "TrimSpace", Pars: "str", Ret: "str", Code: 287, 
+	{Name: "TrimSpace", Pars: "str", Ret: "str", Code: 344, 
 		Func: TrimSpaceºStr, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "Type", Pars: "obj", Ret: "str", Code: 288, 
+	{Name: "TryLock", Pars: "str,int", Ret: "bool", Code: 345, 
+		Func: TryLockºStrInt, Return: core.TYPEBOOL, 
+		Params: []uint16{core.TYPESTR,core.TYPEINT}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "Type", Pars: "obj", Ret: "str", Code: 346, 
 		Func: Type, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPEOBJ}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "UnBase64", Pars: "str", Ret: "buf", Code: 289, 
+	{Name: "UnBase64", Pars: "str", Ret: "buf", Code: 347, 
 		Func: UnBase64ºStr, Return: core.TYPEBUF, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "UnHex", Pars: "str", Ret: "buf", Code: 290, 
+	{Name: "UnHex", Pars: "str", Ret: "buf", Code: 348, 
 		Func: UnHexºStr, Return: core.TYPEBUF, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "Unlock", Pars: "", Ret: "", Code: 291, 
+	{Name: "Unlock", Pars: "", Ret: "", Code: 349, 
 		Func: Unlock, Return: core.TYPENONE, 
 		Params: nil, 
 		Variadic: false, Runtime: true, CanError: false},
-	{Name: "UnSet", Pars: "set,int", Ret: "set", Code: 292, 
+	{Name: "Unlock", Pars: "str", Ret: "", Code: 350, 
+		Func: UnlockºStr, Return: core.TYPENONE, 
+		Params: []uint16{core.TYPESTR}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "UnSet", Pars: "set,int", Ret: "set", Code: 351, 
 		Func: UnSetºSet, Return: core.TYPESET, 
 		Params: []uint16{core.TYPESET,core.TYPEINT}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "Upper", Pars: "str", Ret: "str", Code: 293, 
+	{Name: "Upper", Pars: "str", Ret: "str", Code: 352, 
 		Func: UpperºStr, Return: core.TYPESTR, 
 		Params: []uint16{core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: false},
-	{Name: "UTC", Pars: "time", Ret: "time", Code: 294, 
+	{Name: "UTC", Pars: "time", Ret: "time", Code: 353, 
 		Func: UTCºTime, Return: core.TYPESTRUCT, 
 		Params: []uint16{core.TYPESTRUCT}, 
 		Variadic: false, Runtime: true, CanError: false},
-	{Name: "wait", Pars: "thread", Ret: "", Code: 295, 
+	{Name: "wait", Pars: "thread", Ret: "", Code: 354, 
 		Func: waitºThread, Return: core.TYPENONE, 
 		Params: []uint16{core.TYPEINT}, 
 		Variadic: false, Runtime: true, CanError: true},
-	{Name: "WaitAll", Pars: "", Ret: "", Code: 296, 
+	{Name: "WaitAll", Pars: "", Ret: "", Code: 355, 
 		Func: WaitAll, Return: core.TYPENONE, 
 		Params: nil, 
 		Variadic: false, Runtime: true, CanError: true},
-	{Name: "WaitDone", Pars: "", Ret: "", Code: 297, 
+	{Name: "WaitDone", Pars: "", Ret: "", Code: 356, 
 		Func: WaitDone, Return: core.TYPENONE, 
 		Params: nil, 
 		Variadic: false, Runtime: true, CanError: true},
-	{Name: "WaitGroup", Pars: "int", Ret: "", Code: 298, 
+	{Name: "WaitGroup", Pars: "int", Ret: "", Code: 357, 
 		Func: WaitGroup, Return: core.TYPENONE, 
 		Params: []uint16{core.TYPEINT}, 
 		Variadic: false, Runtime: true, CanError: true},
-	{Name: "Weekday", Pars: "time", Ret: "int", Code: 299, 
+	{Name: "WaitResult", Pars: "thread", Ret: "obj", Code: 358, 
+		Func: WaitResultºThread, Return: core.TYPEOBJ, 
+		Params: []uint16{core.TYPEINT}, 
+		Variadic: false, Runtime: true, CanError: true},
+	{Name: "Weekday", Pars: "time", Ret: "int", Code: 359, 
 		Func: WeekdayºTime, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPESTRUCT}, 
 		Variadic: false, Runtime: true, CanError: false},
-	{Name: "WriteFile", Pars: "str,buf", Ret: "", Code: 300, 
+	{Name: "WriteFile", Pars: "str,buf", Ret: "", Code: 360, 
 		Func: WriteFileºStrBuf, Return: core.TYPENONE, 
 		Params: []uint16{core.TYPESTR,core.TYPEBUF}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "WriteFile", Pars: "str,str", Ret: "", Code: 301, 
+	{Name: "WriteFile", Pars: "str,str", Ret: "", Code: 361, 
 		Func: WriteFileºStrStr, Return: core.TYPENONE, 
 		Params: []uint16{core.TYPESTR,core.TYPESTR}, 
 		Variadic: false, Runtime: false, CanError: true},
-	{Name: "YearDay", Pars: "time", Ret: "int", Code: 302, 
+	{Name: "YearDay", Pars: "time", Ret: "int", Code: 362, 
 		Func: YearDayºTime, Return: core.TYPEINT, 
 		Params: []uint16{core.TYPESTRUCT}, 
 		Variadic: false, Runtime: false, CanError: false},
 }
-const StdLibCount = 303
+const StdLibCount = 363
@@ -23,6 +23,9 @@ type Struct struct {
 	Values []interface{} // Values of fields
 }
 
+// emptyIface is the type of the interface variable which has not been assigned
+var emptyIface = core.StructInfo{}
+
 // NewStruct creates a new struct object
 func NewStruct(rt *Runtime, sInfo *core.StructInfo) *Struct {
 	values := make([]interface{}, len(sInfo.Fields))
@@ -48,6 +51,19 @@ func (pstruct Struct) String() string {
 	return name + `[` + strings.Join(list, ` `) + `]`
 }
 
+// fieldIndex returns the index of the field. Interfaces get fields by names.
+func (pstruct *Struct) fieldIndex(index interface{}) int {
+	if name, ok := index.(string); ok {
+		for i, key := range pstruct.Type.Keys {
+			if key == name {
+				return i
+			}
+		}
+		return -1
+	}
+	return int(index.(int64))
+}
+
 // Len is part of Indexer interface.
 func (pstruct *Struct) Len() int {
 	return len(pstruct.Values)
@@ -55,7 +71,10 @@ func (pstruct *Struct) Len() int {
 
 // GetIndex is part of Indexer interface.
 func (pstruct *Struct) GetIndex(index interface{}) (interface{}, bool) {
-	sindex := int(index.(int64))
+	sindex := pstruct.fieldIndex(index)
+	if pstruct.Type == &emptyIface {
+		return ErrIfaceEmpty, false
+	}
 	if sindex < 0 || sindex >= len(pstruct.Values) {
 		return nil, false
 	}
@@ -64,7 +83,10 @@ func (pstruct *Struct) GetIndex(index interface{}) (interface{}, bool) {
 
 // SetIndex is part of Indexer interface.
 func (pstruct *Struct) SetIndex(index, value interface{}) int {
-	sindex := int(index.(int64))
+	sindex := pstruct.fieldIndex(index)
+	if pstruct.Type == &emptyIface {
+		return ErrIfaceEmpty
+	}
 	if sindex < 0 || sindex >= len(pstruct.Values) {
 		return core.ErrIndexOut
 	}
@@ -39,6 +39,17 @@ func CommandOutput(cmdLine string) (string, error) {
 	return string(stdout), err
 }
 
+// ExitºInt closes all threads and finishes the script with the exit code.
+// The finally blocks and the deferred calls are executed.
+func ExitºInt(rt *Runtime, code int64) error {
+	err := &ExitError{Code: int(code)}
+	rt.Owner.stop(err)
+	// the error of the closed thread cannot be caught
+	rt.setStatus(ThClosed)
+	rt.Thread.Closing = true
+	return err
+}
+
 // GetEnv return the value of the environment variable
 func GetEnv(name string) string {
 	return os.Getenv(name)
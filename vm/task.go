@@ -0,0 +1,234 @@
+// Copyright 2019 Alexey Krivonogov. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// scriptTask is the named task of the script
+type scriptTask struct {
+	Name string
+	Desc string
+	Deps []string
+	Fn   *Fn
+}
+
+// taskResult is the result of the task which has been run in a thread
+type taskResult struct {
+	task *scriptTask
+	err  error
+}
+
+// findTask returns the task by its name
+func findTask(tasks []*scriptTask, name string) *scriptTask {
+	for _, task := range tasks {
+		if task.Name == name {
+			return task
+		}
+	}
+	return nil
+}
+
+// getTasks returns the copy of the declared tasks
+func (rt *Runtime) getTasks() []*scriptTask {
+	rt.Owner.SyncMutex.Lock()
+	defer rt.Owner.SyncMutex.Unlock()
+	return append([]*scriptTask{}, rt.Owner.Tasks...)
+}
+
+// taskList returns the list of the tasks with their descriptions
+func taskList(tasks []*scriptTask) string {
+	var width int
+	for _, task := range tasks {
+		if len(task.Name) > width {
+			width = len(task.Name)
+		}
+	}
+	lines := []string{`Tasks:`}
+	for _, task := range tasks {
+		desc := task.Desc
+		if len(task.Deps) > 0 {
+			desc += fmt.Sprintf(` (depends on: %s)`, strings.Join(task.Deps, `, `))
+		}
+		lines = append(lines, fmt.Sprintf(`  %-*s  %s`, width, task.Name, desc))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// sortTasks returns the specified tasks and their dependencies in the order of running
+func sortTasks(tasks []*scriptTask, names []string) ([]*scriptTask, error) {
+	var (
+		ret   []*scriptTask
+		visit func(name string) error
+	)
+	state := make(map[string]int) // 1 - visiting, 2 - done
+	visit = func(name string) error {
+		task := findTask(tasks, name)
+		if task == nil {
+			return fmt.Errorf(ErrorText(ErrTaskUnknown), name)
+		}
+		switch state[name] {
+		case 1:
+			return fmt.Errorf(ErrorText(ErrTaskCycle), name)
+		case 2:
+			return nil
+		}
+		state[name] = 1
+		for _, dep := range task.Deps {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[name] = 2
+		ret = append(ret, task)
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// runParallel runs every task in a new thread as soon as its dependencies have been finished.
+// The first error closes the running tasks and the rest tasks are skipped.
+func (rt *Runtime) runParallel(tasks []*scriptTask) error {
+	var (
+		first   error
+		running int
+	)
+	waiting := make(map[string]int)
+	for _, task := range tasks {
+		waiting[task.Name] = len(task.Deps)
+	}
+	threads := make(map[string]*Runtime)
+	done := make(chan taskResult, len(tasks))
+	start := func() {
+		for _, task := range tasks {
+			if first != nil || waiting[task.Name] != 0 {
+				continue
+			}
+			waiting[task.Name] = -1
+			thread := rt.Owner.newThread(ThQueue)
+			if thread == nil {
+				first = fmt.Errorf(ErrorText(ErrThreadClosed))
+				return
+			}
+			threads[task.Name] = thread
+			running++
+			var value interface{}
+			CopyVar(rt, &value, task.Fn)
+			copyCaptured(rt, value.(*Fn))
+			go func(task *scriptTask, fn *Fn) {
+				thread.setStatus(ThWork)
+				_, err := thread.callFn(fn)
+				rt.Owner.endThread(thread, nil, err, false)
+				done <- taskResult{task: task, err: err}
+			}(task, value.(*Fn))
+		}
+	}
+	cancel := func() {
+		list := make([]*Runtime, 0, len(threads))
+		for _, thread := range threads {
+			list = append(list, thread)
+		}
+		rt.Owner.closeThreads(list)
+	}
+	start()
+	for running > 0 {
+		chosen, recv, _, errID := rt.waitChan([]reflect.SelectCase{{Dir: reflect.SelectRecv,
+			Chan: reflect.ValueOf(done)}}, false)
+		if chosen < 0 {
+			cancel()
+			if errID == 0 {
+				errID = ErrThreadClosed
+			}
+			return fmt.Errorf(ErrorText(errID))
+		}
+		running--
+		result := recv.Interface().(taskResult)
+		delete(threads, result.task.Name)
+		if result.err != nil && first == nil {
+			first = result.err
+			cancel()
+		}
+		for _, item := range tasks {
+			for _, dep := range item.Deps {
+				if dep == result.task.Name {
+					waiting[item.Name]--
+				}
+			}
+		}
+		start()
+	}
+	return first
+}
+
+// TaskºStrFnStr declares the task with the description and the names of the dependencies
+func TaskºStrFnStr(rt *Runtime, name string, fn *Fn, desc string, deps ...interface{}) error {
+	if fn == nil || fn.Func == 0 {
+		return fmt.Errorf(ErrorText(ErrFnEmpty))
+	}
+	task := &scriptTask{Name: strings.TrimSpace(name), Desc: desc}
+	if len(task.Name) == 0 || strings.HasPrefix(task.Name, `-`) {
+		return fmt.Errorf(ErrorText(ErrTaskDef), name)
+	}
+	for _, dep := range deps {
+		task.Deps = append(task.Deps, fmt.Sprint(dep))
+	}
+	var value interface{}
+	CopyVar(rt, &value, fn)
+	copyCaptured(rt, value.(*Fn))
+	task.Fn = value.(*Fn)
+	vm := rt.Owner
+	vm.SyncMutex.Lock()
+	defer vm.SyncMutex.Unlock()
+	if findTask(vm.Tasks, task.Name) != nil {
+		return fmt.Errorf(ErrorText(ErrTaskDef), name)
+	}
+	vm.Tasks = append(vm.Tasks, task)
+	return nil
+}
+
+// RunTasksºBool runs the tasks specified in the command-line with their dependencies.
+// The parameters which are not the declared options and their values are the names of tasks.
+// If there are no task names then the first declared task is run. If there is -list option
+// then the list of the tasks is printed. Independent tasks are run in parallel if parallel is true.
+func RunTasksºBool(rt *Runtime, parallel int64) error {
+	tasks := rt.getTasks()
+	for _, arg := range rt.Owner.Settings.CmdLine {
+		if arg == `--` {
+			break
+		}
+		if isOption(arg) && strings.TrimLeft(arg, `-`) == `list` {
+			fmt.Print(taskList(tasks))
+			return nil
+		}
+	}
+	_, names, err := parseCmdLine(rt, rt.getOptions())
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 && len(tasks) > 0 {
+		names = append(names, tasks[0].Name)
+	}
+	list, err := sortTasks(tasks, names)
+	if err != nil {
+		return err
+	}
+	if parallel != 0 {
+		return rt.runParallel(list)
+	}
+	for _, task := range list {
+		if _, err = rt.callFn(task.Fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ package vm
 
 import (
 	"fmt"
+	"reflect"
 
 	"github.com/gentee/gentee/core"
 )
@@ -38,10 +39,14 @@ const (
 
 // Thread contains information about a thread
 type Thread struct {
-	Status byte
-	Sleep  int64
-	Chan   chan int
-	Notify []int64 // who waits the end
+	Status  byte
+	Sleep   int64
+	Chan    chan int
+	Notify  []int64       // who waits the end
+	Closing bool          // finally blocks and deferred calls are running after closing the thread
+	Result  interface{}   // the result of go block
+	Err     error         // the error of the thread
+	Done    chan struct{} // it is closed when the thread has finished
 }
 
 /*
@@ -101,11 +106,30 @@ func (rt *RunTime) newThread(status byte) bool {
 */
 func (rt *Runtime) setStatus(status byte) {
 	rt.Owner.ThreadMutex.Lock()
-	rt.Thread.Status = status
+	// the closed thread cannot be resumed
+	if rt.Thread.Status != ThClosed {
+		rt.Thread.Status = status
+	}
 	rt.Owner.ThreadMutex.Unlock()
 }
 
+// threadCount returns the count of the running threads
+func (vm *VM) threadCount() int64 {
+	vm.ThreadMutex.RLock()
+	defer vm.ThreadMutex.RUnlock()
+	return vm.Count
+}
+
+// status returns the current status of the thread
+func (rt *Runtime) status() byte {
+	rt.Owner.ThreadMutex.RLock()
+	defer rt.Owner.ThreadMutex.RUnlock()
+	return rt.Thread.Status
+}
+
 func (vm *VM) newThread(status byte) *Runtime {
+	vm.ThreadMutex.Lock()
+	defer vm.ThreadMutex.Unlock()
 	if len(vm.Runtimes) > 0 && vm.Runtimes[0].Thread.Status >= ThFinished {
 		return nil
 	}
@@ -114,10 +138,11 @@ func (vm *VM) newThread(status byte) *Runtime {
 		Thread: Thread{
 			Status: status,
 			Chan:   make(chan int, 8),
+			Done:   make(chan struct{}),
 		},
 	}
-	vm.ThreadMutex.Lock()
-	defer vm.ThreadMutex.Unlock()
+	rt.initStacks()
+	rt.initMemory()
 	vm.Runtimes = append(vm.Runtimes, rt)
 	rt.ThreadID = int64(len(vm.Runtimes) - 1)
 	if status == ThQueue {
@@ -158,6 +183,9 @@ func (rt *Runtime) GoThread(offset int64, pars []int32, top *Call) int64 {
 		case core.STACKANY:
 			top.Any--
 			CopyVar(rt, &value, rt.SAny[top.Any])
+			if fn, ok := value.(*Fn); ok {
+				copyCaptured(rt, fn)
+			}
 		}
 		optional[i] = OptValue{
 			Var:   int32(i),
@@ -168,28 +196,79 @@ func (rt *Runtime) GoThread(offset int64, pars []int32, top *Call) int64 {
 	thread.Optional = &optional
 
 	go func() {
-		thread.Thread.Status = ThWork
-
-		_, err := thread.Run(offset)
-		rt.Owner.ThreadMutex.Lock()
-		if err != nil {
-			if thread.Thread.Status != ThClosed {
-				thread.Thread.Status = ThError
-				rt.Owner.ChError <- err
-			}
-		} else {
-			thread.Thread.Status = ThFinished
+		thread.setStatus(ThWork)
+
+		result, err := thread.Run(offset)
+		rt.Owner.endThread(thread, result, err, !rt.Owner.Settings.NonFatalThreads)
+	}()
+	return thread.ThreadID
+}
+
+// copyCaptured gives fn its own copy of the captured variables
+func copyCaptured(rt *Runtime, fn *Fn) {
+	if fn.Captured == nil {
+		return
+	}
+	captured := make([]OptValue, len(*fn.Captured))
+	for j, item := range *fn.Captured {
+		captured[j] = item
+		if item.Type&0xf == core.STACKANY {
+			captured[j].Value = nil
+			CopyVar(rt, &captured[j].Value, item.Value)
 		}
-		close(thread.Thread.Chan)
-		for _, nfyid := range thread.Thread.Notify {
-			if rt.Owner.Runtimes[nfyid].Thread.Status == ThWait {
-				rt.Owner.Runtimes[nfyid].Thread.Chan <- ThCmdContinue
+	}
+	fn.Captured = &captured
+}
+
+// endThread saves the result of the finished thread and notifies the waiting threads.
+// The error of the thread stops the script if fatal is true.
+func (vm *VM) endThread(thread *Runtime, result interface{}, err error, fatal bool) {
+	thread.freeMemory()
+	thread.releaseGas()
+	vm.releaseLocks(thread.ThreadID)
+	vm.ThreadMutex.Lock()
+	thread.Thread.Result = result
+	thread.Thread.Err = err
+	if err != nil {
+		if thread.Thread.Status != ThClosed {
+			thread.Thread.Status = ThError
+			if fatal {
+				vm.ChError <- err
 			}
 		}
-		rt.Owner.ThreadMutex.Unlock()
-		rt.Owner.ChCount <- 1
-	}()
-	return thread.ThreadID
+	} else {
+		thread.Thread.Status = ThFinished
+	}
+	close(thread.Thread.Chan)
+	close(thread.Thread.Done)
+	for _, nfyid := range thread.Thread.Notify {
+		if vm.Runtimes[nfyid].Thread.Status == ThWait {
+			vm.Runtimes[nfyid].Thread.Chan <- ThCmdContinue
+		}
+	}
+	vm.ThreadMutex.Unlock()
+	vm.ChCount <- 1
+}
+
+// stop closes all threads including the main thread. err is returned as the result of the script.
+func (vm *VM) stop(err error) {
+	vm.ThreadMutex.Lock()
+	if vm.Stopped == nil {
+		vm.Stopped = err
+	}
+	vm.ThreadMutex.Unlock()
+	vm.closeAll()
+}
+
+// closeThreads sends the close command to the working threads
+func (vm *VM) closeThreads(threads []*Runtime) {
+	vm.ThreadMutex.Lock()
+	for _, thread := range threads {
+		if thread.Thread.Status < ThFinished {
+			thread.Thread.Chan <- ThCmdClose
+		}
+	}
+	vm.ThreadMutex.Unlock()
 }
 
 // Lock locks vm mutex
@@ -257,6 +336,57 @@ func waitºThread(rt *Runtime, threadID int64) error {
 	})
 }
 
+// WaitResultºThread waits for the end of the thread and returns its result or its error
+func WaitResultºThread(rt *Runtime, threadID int64) (*core.Obj, error) {
+	var thread *Runtime
+	if err := changeStatus(rt, threadID, func(vm *VM) {
+		thread = vm.Runtimes[threadID]
+	}); err != nil {
+		return nil, err
+	}
+	chosen, _, _, errID := rt.waitChan([]reflect.SelectCase{{Dir: reflect.SelectRecv,
+		Chan: reflect.ValueOf(thread.Thread.Done)}}, false)
+	if chosen < 0 {
+		if errID == 0 {
+			errID = ErrThreadClosed
+		}
+		return nil, fmt.Errorf(ErrorText(errID))
+	}
+	if thread.Thread.Err != nil {
+		return nil, thread.Thread.Err
+	}
+	switch v := thread.Thread.Result.(type) {
+	case nil:
+		return core.NewObj(), nil
+	case rune:
+		return objType(string(v))
+	}
+	return objType(thread.Thread.Result)
+}
+
+// ThreadStatusºThread returns the status of the thread
+func ThreadStatusºThread(rt *Runtime, threadID int64) (status int64, err error) {
+	rt.Owner.ThreadMutex.RLock()
+	defer rt.Owner.ThreadMutex.RUnlock()
+	if threadID < 0 || int64(len(rt.Owner.Runtimes)) <= threadID {
+		return 0, fmt.Errorf(ErrorText(ErrThreadIndex))
+	}
+	return int64(rt.Owner.Runtimes[threadID].Thread.Status), nil
+}
+
+// Threads returns the list of active threads
+func Threads(rt *Runtime) *core.ArrayInt {
+	ret := core.NewArrayInt()
+	rt.Owner.ThreadMutex.RLock()
+	defer rt.Owner.ThreadMutex.RUnlock()
+	for i := 1; i < len(rt.Owner.Runtimes); i++ {
+		if rt.Owner.Runtimes[i].Thread.Status < ThFinished {
+			ret.Data = append(ret.Data, int64(i))
+		}
+	}
+	return ret
+}
+
 // WaitAll blocks until the WaitGroup counter is zero
 func WaitAll(rt *Runtime) error {
 	if rt.ThreadID != 0 {
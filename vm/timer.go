@@ -0,0 +1,204 @@
+// Copyright 2019 Alexey Krivonogov. All rights reserved.
+// Use of this source code is governed by a MIT license
+// that can be found in the LICENSE file.
+
+package vm
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule contains the bit masks of the allowed values of cron fields
+type cronSchedule struct {
+	minute     uint64
+	hour       uint64
+	day        uint64
+	month      uint64
+	weekday    uint64
+	anyDay     bool
+	anyWeekday bool
+}
+
+// cronLimits are the ranges of minutes, hours, days of month, months and days of week
+var cronLimits = [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+
+// parseCron parses five-field cron expression. Every field can be *, a number, a range
+// or a list of them with optional /step.
+func parseCron(expr string) (*cronSchedule, error) {
+	fields := strings.Fields(expr)
+	errCron := fmt.Errorf(ErrorText(ErrCron), expr)
+	if len(fields) != 5 {
+		return nil, errCron
+	}
+	var (
+		masks [5]uint64
+		err   error
+	)
+	for i, field := range fields {
+		for _, item := range strings.Split(field, `,`) {
+			from, to, step := cronLimits[i][0], cronLimits[i][1], 1
+			k := strings.IndexByte(item, '/')
+			if k >= 0 {
+				if step, err = strconv.Atoi(item[k+1:]); err != nil || step < 1 {
+					return nil, errCron
+				}
+				item = item[:k]
+			}
+			if item != `*` {
+				bounds := strings.SplitN(item, `-`, 2)
+				if from, err = strconv.Atoi(bounds[0]); err != nil {
+					return nil, errCron
+				}
+				if len(bounds) == 2 {
+					if to, err = strconv.Atoi(bounds[1]); err != nil {
+						return nil, errCron
+					}
+				} else if k < 0 {
+					to = from
+				}
+				if from < cronLimits[i][0] || to > cronLimits[i][1] || from > to {
+					return nil, errCron
+				}
+			}
+			for v := from; v <= to; v += step {
+				masks[i] |= 1 << uint(v)
+			}
+		}
+	}
+	if masks[4]&(1<<7) != 0 {
+		// 7 is Sunday too
+		masks[4] |= 1
+	}
+	return &cronSchedule{minute: masks[0], hour: masks[1], day: masks[2], month: masks[3],
+		weekday: masks[4], anyDay: strings.HasPrefix(fields[2], `*`),
+		anyWeekday: strings.HasPrefix(fields[4], `*`)}, nil
+}
+
+// matchDay checks the day of month and the day of week. If both fields are restricted
+// then the day matches if one of them matches.
+func (cron *cronSchedule) matchDay(t time.Time) bool {
+	day := cron.day&(1<<uint(t.Day())) != 0
+	weekday := cron.weekday&(1<<uint(t.Weekday())) != 0
+	switch {
+	case cron.anyDay && cron.anyWeekday:
+		return true
+	case cron.anyDay:
+		return weekday
+	case cron.anyWeekday:
+		return day
+	}
+	return day || weekday
+}
+
+// next returns the first time after t which matches the schedule or zero time
+func (cron *cronSchedule) next(t time.Time) time.Time {
+	loc := t.Location()
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, loc)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case cron.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !cron.matchDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case cron.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case cron.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// timerThread calls fn in a new thread after every delay which next returns.
+// The thread is finished when next returns a negative delay or the thread is closed.
+func (rt *Runtime) timerThread(fn *Fn, next func() time.Duration) (int64, error) {
+	if fn == nil || fn.Func == 0 {
+		return 0, fmt.Errorf(ErrorText(ErrFnEmpty))
+	}
+	thread := rt.Owner.newThread(ThQueue)
+	if thread == nil {
+		return 0, fmt.Errorf(ErrorText(ErrThreadClosed))
+	}
+	var value interface{}
+	CopyVar(rt, &value, fn)
+	copyCaptured(rt, value.(*Fn))
+	go func(fn *Fn) {
+		thread.setStatus(ThWork)
+		var err error
+		for delay := next(); delay >= 0 && err == nil; delay = next() {
+			timer := time.NewTimer(delay)
+			chosen, _, _, _ := thread.waitChan([]reflect.SelectCase{{Dir: reflect.SelectRecv,
+				Chan: reflect.ValueOf(timer.C)}}, true)
+			timer.Stop()
+			if chosen < 0 {
+				err = fmt.Errorf(ErrorText(ErrThreadClosed))
+				break
+			}
+			_, err = thread.callFn(fn)
+		}
+		rt.Owner.endThread(thread, nil, err, !rt.Owner.Settings.NonFatalThreads)
+	}(value.(*Fn))
+	return thread.ThreadID, nil
+}
+
+// AfterºIntFn calls fn in a new thread after the specified duration in milliseconds
+func AfterºIntFn(rt *Runtime, d int64, fn *Fn) (int64, error) {
+	delay := time.Duration(d) * time.Millisecond
+	if delay < 0 {
+		delay = 0
+	}
+	return rt.timerThread(fn, func() time.Duration {
+		ret := delay
+		delay = -1
+		return ret
+	})
+}
+
+// EveryºIntFn calls fn in a new thread every the specified duration in milliseconds
+func EveryºIntFn(rt *Runtime, d int64, fn *Fn) (int64, error) {
+	if d <= 0 {
+		return 0, fmt.Errorf(ErrorText(ErrInvalidParam))
+	}
+	return rt.timerThread(fn, func() time.Duration {
+		return time.Duration(d) * time.Millisecond
+	})
+}
+
+// NextCronºStrTime returns the first time after t which matches cron expression
+func NextCronºStrTime(rt *Runtime, expr string, t *Struct) (*Struct, error) {
+	ret := newTime(rt)
+	cron, err := parseCron(expr)
+	if err != nil {
+		return ret, err
+	}
+	next := cron.next(toTime(t))
+	if next.IsZero() {
+		return ret, fmt.Errorf(ErrorText(ErrCron), expr)
+	}
+	return fromTime(ret, next), nil
+}
+
+// ScheduleºStrFn calls fn in a new thread at the times which match cron expression
+func ScheduleºStrFn(rt *Runtime, expr string, fn *Fn) (int64, error) {
+	cron, err := parseCron(expr)
+	if err != nil {
+		return 0, err
+	}
+	if cron.next(time.Now()).IsZero() {
+		return 0, fmt.Errorf(ErrorText(ErrCron), expr)
+	}
+	return rt.timerThread(fn, func() time.Duration {
+		next := cron.next(time.Now())
+		if next.IsZero() {
+			return -1
+		}
+		return time.Until(next)
+	})
+}
@@ -12,7 +12,8 @@ import (
 
 // Fn is used for custom func types
 type Fn struct {
-	Func int32 // id of function
+	Func     int32       // id of function
+	Captured *[]OptValue // captured variables of the function literal
 }
 
 // CopyVar copies one object to another one
@@ -26,6 +27,7 @@ func CopyVar(rt *Runtime, ptr *interface{}, value interface{}) {
 			pfn = (*ptr).(*Fn)
 		}
 		pfn.Func = vItem.Func
+		pfn.Captured = vItem.Captured
 		*ptr = pfn
 	case *Struct:
 		var pstruct *Struct
@@ -71,6 +73,26 @@ func CopyVar(rt *Runtime, ptr *interface{}, value interface{}) {
 			CopyVar(rt, &parr.Data[i], v)
 		}
 		*ptr = parr
+	case *core.ArrayInt:
+		var parr *core.ArrayInt
+		if ptr == nil || *ptr == nil {
+			parr = core.NewArrayInt()
+		} else {
+			parr = (*ptr).(*core.ArrayInt)
+		}
+		parr.Data = make([]int64, len(vItem.Data))
+		copy(parr.Data, vItem.Data)
+		*ptr = parr
+	case *core.ArrayFloat:
+		var parr *core.ArrayFloat
+		if ptr == nil || *ptr == nil {
+			parr = core.NewArrayFloat()
+		} else {
+			parr = (*ptr).(*core.ArrayFloat)
+		}
+		parr.Data = make([]float64, len(vItem.Data))
+		copy(parr.Data, vItem.Data)
+		*ptr = parr
 	case *core.Map:
 		var pmap *core.Map
 		if ptr == nil || *ptr == nil {
@@ -124,6 +146,10 @@ func newValue(rt *Runtime, vtype int) interface{} {
 		return float64(0.0)
 	case core.TYPEARR:
 		return core.NewArray()
+	case core.TYPEARRINT:
+		return core.NewArrayInt()
+	case core.TYPEARRFLOAT:
+		return core.NewArrayFloat()
 	case core.TYPEMAP:
 		return core.NewMap()
 	case core.TYPEBUF:
@@ -136,6 +162,10 @@ func newValue(rt *Runtime, vtype int) interface{} {
 		return core.NewSet()
 	case core.TYPEOBJ:
 		return core.NewObj()
+	case core.TYPEIFACE:
+		return &Struct{Type: &emptyIface}
+	case core.TYPECHAN:
+		return core.NewChan(0)
 	default:
 		if vtype >= core.TYPESTRUCT {
 			return NewStruct(rt, &rt.Owner.Exec.Structs[(vtype-core.TYPESTRUCT)>>8])
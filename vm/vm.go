@@ -6,6 +6,7 @@ package vm
 
 import (
 	"fmt"
+	"os"
 	"sync"
 
 	"github.com/gentee/gentee/core"
@@ -14,18 +15,35 @@ import (
 //go:generate go run generate/generate.go
 
 const (
-	STACKSIZE = 256
+	// STACKSIZE is the initial size of each stack of the thread
+	STACKSIZE = 32
+	// STACKGAP is the minimum count of free items in stacks before a command
+	STACKGAP = 8
 	// CYCLE is the limit of loops
 	CYCLE = uint64(16000000)
-	// DEPTH is the maximum size of blocks stack
-	DEPTH = uint32(1000)
+	// CALLSMEMORY is the maximum memory size of the blocks stack if there is not MaxMemory
+	CALLSMEMORY = 64 << 20
+	// TAILTRACE is the count of tail calls of one frame which are kept for the trace
+	TAILTRACE = 8
+	// STACK is the maximum size of each stack of the thread
+	STACK = uint32(1000000)
+	// NESTED is the maximum depth of fn calls from embedded functions
+	NESTED = 1000
 )
 
 type Settings struct {
 	CmdLine []string
 	Input   []byte // stdin
 	Cycle   uint64 // limit of loops
-	Depth   uint32 // limit of blocks stack
+	Depth   uint32 // limit of blocks stack, 0 means that it is limited by memory
+	Stack   uint32 // limit of each data stack
+	// MaxMemory is the limit of the approximate memory usage in bytes. 0 means no limit.
+	MaxMemory int64
+	Gas       uint64 // limit of gas, 0 means no limit
+	Stats     *Stats // if it is not nil, it gets the statistics after the run
+	// NonFatalThreads means that the error of the thread doesn't stop the script.
+	// The error can be got with WaitResult.
+	NonFatalThreads bool
 }
 
 type Const struct {
@@ -35,6 +53,9 @@ type Const struct {
 
 // VM is the main structure of the virtual machine
 type VM struct {
+	Memory      int64  // approximate memory usage of all threads
+	PeakMemory  int64  // peak of Memory
+	Gas         uint64 // gas spent by all threads
 	Settings    Settings
 	Exec        *core.Exec
 	Consts      map[int32]Const
@@ -42,9 +63,19 @@ type VM struct {
 	CtxMutex    sync.RWMutex
 	ThreadMutex sync.RWMutex
 	LockMutex   sync.Mutex
+	SyncMutex   sync.Mutex // it protects Locks, Counters, Signals, Options and Tasks
+	Locks       map[string]*namedLock
+	Counters    map[string]*int64
+	Signals     map[os.Signal]*Fn // nil fn means the ignored signal
+	ChSignal    chan os.Signal
+	Stopped     error // the reason of stopping all threads of the script
+	Options     []*cmdOption
+	Tasks       []*scriptTask
 	WaitGroup   sync.WaitGroup
 	Context     map[string]string
 	Count       int64 // count of active threads
+	Blocked     int64 // count of threads which are waiting in waitChan without timers
+	Unblocked   int64 // count of exits from waitChan, it is used to detect deadlocks
 	WaitCount   int64
 	ChCount     chan int64
 	ChError     chan error
@@ -65,24 +96,34 @@ type Runtime struct {
 	Thread   Thread
 	ThreadID int64
 	Optional *[]OptValue
+	Top      Call  // the top of stacks when the embedded function is called
+	Nested   int32 // the depth of fn calls from embedded functions
+	// memory accounting
+	Memory    int64 // memory usage at the last measurement
+	Allocated int64 // size of allocations after the last measurement
+	MemStep   int64 // size of allocations when the memory is measured again
+	// gas metering
+	GasLeft int64 // the rest of the gas reserved by the thread, it is negative if overspent
 	// These are stacks for different types
-	SInt   [STACKSIZE]int64       // int, char, bool
-	SFloat [STACKSIZE]float64     // float
-	SStr   [STACKSIZE]string      // str
-	SAny   [STACKSIZE]interface{} // all other types
+	SInt   []int64       // int, char, bool
+	SFloat []float64     // float
+	SStr   []string      // str
+	SAny   []interface{} // all other types
 }
 
 // Call stores stack of blocks
 type Call struct {
-	IsFunc   bool
-	IsLocal  bool
-	Cycle    uint64
-	Offset   int32
-	Int      int32
-	Float    int32
-	Str      int32
-	Any      int32
-	Optional *[]OptValue
+	IsFunc     bool
+	IsLocal    bool
+	IsCallback bool // fn is called from the embedded function
+	Cycle      uint64
+	Offset     int32
+	Int        int32
+	Float      int32
+	Str        int32
+	Any        int32
+	Optional   *[]OptValue
+	Tail       *tailTrace // tail calls which have reused the frame of the function
 	// for loop blocks
 	Flags    int16
 	Start    int32
@@ -91,16 +132,145 @@ type Call struct {
 	Try      int32 // shift for try
 	Recover  int32 // shift for recover
 	Retry    int32 // shift for retry
+	Finally  int32 // shift for finally
+	Pending  int32 // position+1 of the command which is continued after finally, -1 for error
+}
+
+// tailTrace stores the positions of tail calls of the frame. The first TAILTRACE-1 calls and
+// the last call are kept, the rest calls are only counted.
+type tailTrace struct {
+	Offsets []int32
+	Elided  int64
 }
 
 func (vm *VM) runConsts(offset int64) (interface{}, error) {
 	rt := &Runtime{
 		Owner: vm,
 	}
+	rt.initStacks()
+	rt.initMemory()
 	vm.Runtimes = append(vm.Runtimes, rt)
+	defer rt.releaseGas()
 	return rt.Run(offset)
 }
 
+func (rt *Runtime) initStacks() {
+	rt.SInt = make([]int64, STACKSIZE)
+	rt.SFloat = make([]float64, STACKSIZE)
+	rt.SStr = make([]string, STACKSIZE)
+	rt.SAny = make([]interface{}, STACKSIZE)
+}
+
+// growStacks enlarges the stacks which have less than count free items.
+// It returns ErrStack if the new size exceeds the limit of stacks.
+func (rt *Runtime) growStacks(top *Call, count int32) int {
+	newSize := func(size int, cur int32) int {
+		need := int(cur + count)
+		if need <= size {
+			return 0
+		}
+		if need > int(rt.Owner.Settings.Stack) {
+			return -1
+		}
+		for size < need {
+			size <<= 1
+		}
+		if size > int(rt.Owner.Settings.Stack) {
+			size = int(rt.Owner.Settings.Stack)
+		}
+		return size
+	}
+	sizes := [4]int{newSize(len(rt.SInt), top.Int), newSize(len(rt.SFloat), top.Float),
+		newSize(len(rt.SStr), top.Str), newSize(len(rt.SAny), top.Any)}
+	for _, size := range sizes {
+		if size < 0 {
+			return ErrStack
+		}
+	}
+	if sizes[0] > 0 {
+		rt.SInt = append(rt.SInt, make([]int64, sizes[0]-len(rt.SInt))...)
+	}
+	if sizes[1] > 0 {
+		rt.SFloat = append(rt.SFloat, make([]float64, sizes[1]-len(rt.SFloat))...)
+	}
+	if sizes[2] > 0 {
+		rt.SStr = append(rt.SStr, make([]string, sizes[2]-len(rt.SStr))...)
+	}
+	if sizes[3] > 0 {
+		rt.SAny = append(rt.SAny, make([]interface{}, sizes[3]-len(rt.SAny))...)
+	}
+	return 0
+}
+
+// runNested is Runtime.run. It is assigned in init because the embedded functions
+// calling fn make the initialization cycle with EmbedFuncs.
+var runNested func(rt *Runtime, i int64, top Call) (interface{}, error)
+
+func init() {
+	runNested = (*Runtime).run
+}
+
+// callFn calls fn value from the embedded function. The parameters are pushed above
+// the top of stacks of the embedded function and the nested run returns the result.
+func (rt *Runtime) callFn(fn *Fn, pars ...interface{}) (interface{}, error) {
+	if fn == nil || fn.Func == 0 {
+		return nil, fmt.Errorf(ErrorText(ErrFnEmpty))
+	}
+	if rt.Nested >= NESTED {
+		return nil, fmt.Errorf(ErrorText(ErrDepth))
+	}
+	base := rt.Top
+	top := base
+	if errID := rt.growStacks(&top, int32(len(pars))+STACKGAP); errID != 0 {
+		return nil, fmt.Errorf(ErrorText(errID))
+	}
+	for _, par := range pars {
+		switch v := par.(type) {
+		case int64:
+			rt.SInt[top.Int] = v
+			top.Int++
+		case float64:
+			rt.SFloat[top.Float] = v
+			top.Float++
+		case string:
+			rt.SStr[top.Str] = v
+			top.Str++
+		default:
+			rt.SAny[top.Any] = v
+			top.Any++
+		}
+	}
+	calls, optional, parCount := rt.Calls, rt.Optional, rt.ParCount
+	// the nested run sees only its own calls so it can't unwind to the try of the caller
+	rt.Calls = append(calls[len(calls):], Call{
+		IsFunc:     true,
+		IsCallback: true,
+		Offset:     int32(len(rt.Owner.Exec.Code) - 1),
+		Int:        top.Int,
+		Float:      top.Float,
+		Str:        top.Str,
+		Any:        top.Any,
+		Optional:   fn.Captured,
+	})
+	rt.Optional = nil
+	rt.ParCount = int32(len(pars))
+	rt.Nested++
+	result, err := runNested(rt, int64(rt.Owner.Exec.Funcs[fn.Func]), top)
+	rt.Nested--
+	rt.Calls, rt.Optional, rt.ParCount = calls, optional, parCount
+	rt.Top = base
+	switch v := result.(type) {
+	case bool:
+		if v {
+			return int64(1), err
+		}
+		return int64(0), err
+	case rune:
+		return int64(v), err
+	}
+	return result, err
+}
+
 func Run(exec *core.Exec, settings Settings) (interface{}, error) {
 	if exec == nil {
 		return nil, fmt.Errorf(ErrorText(ErrNotRun))
@@ -113,16 +283,32 @@ func Run(exec *core.Exec, settings Settings) (interface{}, error) {
 		Exec:     exec,
 		Consts:   make(map[int32]Const),
 		Context:  make(map[string]string),
+		Locks:    make(map[string]*namedLock),
+		Counters: make(map[string]*int64),
+		Signals:  make(map[os.Signal]*Fn),
 		Runtimes: make([]*Runtime, 0, 32),
 		ChCount:  make(chan int64, 16),
 		ChError:  make(chan error, 16),
 		ChWait:   make(chan int64, 16),
 	}
+	for _, name := range exec.Env {
+		if _, ok := os.LookupEnv(name); !ok {
+			return nil, fmt.Errorf(ErrorText(ErrEnvRequired), name)
+		}
+	}
+	if vm.Settings.Cycle == 0 {
+		vm.Settings.Cycle = exec.Cycle
+	}
 	if vm.Settings.Cycle == 0 {
 		vm.Settings.Cycle = CYCLE
 	}
 	if vm.Settings.Depth == 0 {
-		vm.Settings.Depth = DEPTH
+		vm.Settings.Depth = exec.Depth
+	}
+	if vm.Settings.Stack == 0 {
+		vm.Settings.Stack = STACK
+	} else if vm.Settings.Stack < STACKSIZE {
+		vm.Settings.Stack = STACKSIZE
 	}
 	//	fmt.Println(`CODE`, vm.Exec.Code)
 	//fmt.Println(`POS`, vm.Exec.Pos)
@@ -136,7 +322,7 @@ func Run(exec *core.Exec, settings Settings) (interface{}, error) {
 		}
 		switch id - iotaShift {
 		case core.ConstDepthID:
-			vm.Consts[id] = Const{Type: core.TYPEINT, Value: int64(vm.Settings.Depth)}
+			vm.Consts[id] = Const{Type: core.TYPEINT, Value: vm.depthLimit()}
 			continue
 		case core.ConstCycleID:
 			vm.Consts[id] = Const{Type: core.TYPEINT, Value: int64(vm.Settings.Cycle)}
@@ -173,6 +359,7 @@ func Run(exec *core.Exec, settings Settings) (interface{}, error) {
 		vm.Consts[id] = Const{Type: constType, Value: val}
 	}
 	vm.Runtimes = vm.Runtimes[:0]
+	vm.Memory = 0
 	rt := vm.newThread(ThWork)
 	go func() {
 		x := int64(1)
@@ -188,10 +375,11 @@ func Run(exec *core.Exec, settings Settings) (interface{}, error) {
 		}
 	}()
 	result, errResult := rt.Run(0)
+	rt.releaseGas()
 	if errResult != nil {
 		vm.closeAll()
 	}
-	for vm.Count > 0 {
+	for vm.threadCount() > 0 {
 		select {
 		case err := <-vm.ChError:
 			vm.closeAll()
@@ -201,11 +389,18 @@ func Run(exec *core.Exec, settings Settings) (interface{}, error) {
 		default:
 		}
 	}
+	vm.resetSignals()
+	if vm.Stopped != nil {
+		errResult = vm.Stopped
+	}
 	vm.ChCount <- 0
 	close(vm.Runtimes[0].Thread.Chan)
 	close(vm.ChCount)
 	close(vm.ChError)
-
+	if vm.Settings.Stats != nil {
+		vm.Settings.Stats.PeakMemory = vm.peakMemory()
+		vm.Settings.Stats.Gas = vm.Gas
+	}
 	return result, errResult
 
 }